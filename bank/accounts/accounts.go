@@ -0,0 +1,530 @@
+package accounts
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"example.com/bank/currency"
+	"example.com/bank/events"
+	"example.com/bank/fileutils"
+	"example.com/bank/ledger"
+	"example.com/bank/money"
+)
+
+const MainAccount = "main"
+
+const (
+	accountsFile       = "accounts.json"
+	accountBalanceFile = "balance.txt"
+	historyFile        = "history.jsonl"
+	ratesFile          = "rates.json"
+)
+
+// A snapshot of the state is saved every snapshotInterval events.
+const snapshotInterval = 100
+
+var (
+	ErrUnknownAccount    = errors.New("account does not exist")
+	ErrAccountExists     = errors.New("account already exists")
+	ErrInvalidName       = errors.New("account name may only contain lowercase letters, digits, '-' and '_'")
+	ErrInvalidAmount     = errors.New("amount must be greater then 0")
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrSameAccount       = errors.New("cannot transfer to the same account")
+	ErrBalanceNotSaved   = errors.New("transaction recorded but balance file could not be updated")
+	ErrUnknownProduct    = errors.New("account product does not exist")
+)
+
+var accountNamePattern = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// Service owns the accounts stored in one directory. All operations are
+// serialized by a mutex, so a Service can be shared between goroutines, but
+// only one process should use a directory at a time.
+//
+// Every change is appended to the event log before the balance files are
+// written. The event log is the source of truth and NewService repairs
+// balance files left behind by an interrupted write.
+type Service struct {
+	mu       sync.Mutex
+	dir      string
+	products []Product
+	rates    currency.Table
+	accounts []Account
+	log      *events.Log
+	state    events.State
+}
+
+func NewService(dir string) (*Service, error) {
+	service := &Service{dir: dir}
+	err := service.loadProducts()
+	if err != nil {
+		return nil, err
+	}
+	service.rates, err = currency.Load(service.path(ratesFile))
+	if err != nil {
+		return nil, err
+	}
+	err = service.loadAccounts()
+	if err != nil {
+		return nil, err
+	}
+	err = service.openLog()
+	if err != nil {
+		return nil, err
+	}
+	return service, nil
+}
+
+// Close waits for the operation in progress to finish and blocks all later
+// ones, so the process can exit without cutting a write short.
+func (service *Service) Close() {
+	service.mu.Lock()
+}
+
+func (service *Service) Accounts() []string {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	names := []string{}
+	for _, account := range service.accounts {
+		names = append(names, account.Name)
+	}
+	return names
+}
+
+func (service *Service) Exists(account string) bool {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	return service.find(account) != nil
+}
+
+func (service *Service) Details(name string) (Account, error) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	account := service.find(name)
+	if account == nil {
+		return Account{}, ErrUnknownAccount
+	}
+	return *account, nil
+}
+
+func (service *Service) Open(name, productName, currencyCode string) error {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	if !accountNamePattern.MatchString(name) {
+		return ErrInvalidName
+	}
+	if service.find(name) != nil {
+		return ErrAccountExists
+	}
+	currencyCode = strings.ToUpper(currencyCode)
+	if !service.rates.Known(currencyCode) {
+		return currency.ErrUnknownCurrency
+	}
+	index := slices.IndexFunc(service.products, func(product Product) bool {
+		return product.Name == productName
+	})
+	if index < 0 {
+		return ErrUnknownProduct
+	}
+	account := Account{
+		Name:        name,
+		Currency:    currencyCode,
+		Product:     service.products[index],
+		LastAccrual: today(time.Now()),
+	}
+	opened := events.Account{Name: name, Currency: currencyCode, Product: account.Product.Name}
+	err := service.record(events.Opened(opened, time.Now()))
+	if err != nil {
+		return err
+	}
+	service.accounts = append(service.accounts, account)
+	err = fileutils.WriteToFile(0, service.balanceFile(name))
+	if err != nil {
+		return ErrBalanceNotSaved
+	}
+	return service.saveAccounts(service.accounts)
+}
+
+func (service *Service) Balance(account string) (money.Money, error) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	if service.find(account) == nil {
+		return 0, ErrUnknownAccount
+	}
+	return service.state.Balances[account], nil
+}
+
+func (service *Service) Deposit(account string, amount money.Money, details ledger.Details) (ledger.Transaction, error) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	if service.find(account) == nil {
+		return ledger.Transaction{}, ErrUnknownAccount
+	}
+	if amount <= 0 {
+		return ledger.Transaction{}, ErrInvalidAmount
+	}
+	balance := service.state.Balances[account] + amount
+	return service.commit(ledger.NewDeposit(account, amount, balance, details))
+}
+
+// Withdraw takes money from the account. Checking accounts may go below zero
+// up to their overdraft limit; every withdrawal that leaves the account
+// overdrawn is followed by an overdraft fee entry.
+func (service *Service) Withdraw(name string, amount money.Money, details ledger.Details) (ledger.Transaction, error) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	account := service.find(name)
+	if account == nil {
+		return ledger.Transaction{}, ErrUnknownAccount
+	}
+	if amount <= 0 {
+		return ledger.Transaction{}, ErrInvalidAmount
+	}
+	if !service.covers(account, amount) {
+		return ledger.Transaction{}, ErrInsufficientFunds
+	}
+	balance := service.state.Balances[name] - amount
+	transaction, err := service.commit(ledger.NewWithdrawal(name, amount, balance, details))
+	if err != nil {
+		return transaction, err
+	}
+	return transaction, service.chargeOverdraftFee(account)
+}
+
+// Transfer moves money between two accounts as one ledger transaction
+// holding both postings, so a crash never leaves only one side applied.
+// Amount is in the source account's currency; transfers to an account in
+// another currency are converted with the local exchange rate table and the
+// rate and fee are kept on the ledger entry.
+func (service *Service) Transfer(from, to string, amount money.Money, details ledger.Details) (ledger.Transaction, error) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	if from == to {
+		return ledger.Transaction{}, ErrSameAccount
+	}
+	account := service.find(from)
+	target := service.find(to)
+	if account == nil || target == nil {
+		return ledger.Transaction{}, ErrUnknownAccount
+	}
+	if amount <= 0 {
+		return ledger.Transaction{}, ErrInvalidAmount
+	}
+	if !service.covers(account, amount) {
+		return ledger.Transaction{}, ErrInsufficientFunds
+	}
+	credit, rate, fee, err := service.rates.Convert(amount, account.Currency, target.Currency)
+	if err != nil {
+		return ledger.Transaction{}, err
+	}
+	fromBalance := service.state.Balances[from] - amount
+	toBalance := service.state.Balances[to] + credit
+	transaction := ledger.NewTransfer(from, to, amount, fromBalance, toBalance, details)
+	if account.Currency != target.Currency {
+		exchange := ledger.Exchange{From: account.Currency, To: target.Currency, Rate: rate, Fee: fee}
+		transaction = ledger.NewExchangeTransfer(from, to, amount, credit, fromBalance, toBalance, exchange, details)
+	}
+	transaction, err = service.commit(transaction)
+	if err != nil {
+		return transaction, err
+	}
+	return transaction, service.chargeOverdraftFee(account)
+}
+
+// NetWorth adds up the balances of all accounts converted to base at the
+// current exchange rates, without conversion fees.
+func (service *Service) NetWorth(base string) (money.Money, error) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	var total money.Money
+	for _, account := range service.accounts {
+		value, err := service.rates.Value(service.state.Balances[account.Name], account.Currency, strings.ToUpper(base))
+		if err != nil {
+			return 0, err
+		}
+		total += value
+	}
+	return total, nil
+}
+
+func (service *Service) Currencies() []string {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	return service.rates.Currencies()
+}
+
+// History returns the account's transactions between from and to, both
+// inclusive. A zero time leaves that side of the range open.
+func (service *Service) History(account string, from, to time.Time) ([]ledger.Transaction, error) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	if service.find(account) == nil {
+		return nil, ErrUnknownAccount
+	}
+	transactions, err := service.transactions()
+	if err != nil {
+		return nil, err
+	}
+	return ledger.Filter(transactions, account, from, to), nil
+}
+
+// commit records the transaction in the event log and then writes the
+// balance files. Once the event is in the log the transaction stands; a
+// failed balance file write is reported as ErrBalanceNotSaved and repaired
+// from the log on the next start.
+func (service *Service) commit(transaction ledger.Transaction) (ledger.Transaction, error) {
+	err := service.record(events.Recorded(transaction))
+	if err != nil {
+		return ledger.Transaction{}, err
+	}
+	var writeErr error
+	for _, posting := range transaction.Postings {
+		err = fileutils.WriteToFile(posting.Balance, service.balanceFile(posting.Account))
+		if err != nil {
+			writeErr = ErrBalanceNotSaved
+		}
+	}
+	return transaction, writeErr
+}
+
+func (service *Service) path(fileName string) string {
+	return filepath.Join(service.dir, fileName)
+}
+
+func (service *Service) balanceFile(account string) string {
+	if account == MainAccount {
+		return service.path(accountBalanceFile)
+	}
+	return service.path("balance_" + account + ".txt")
+}
+
+func (service *Service) find(name string) *Account {
+	for i := range service.accounts {
+		if service.accounts[i].Name == name {
+			return &service.accounts[i]
+		}
+	}
+	return nil
+}
+
+// loadAccounts also reads the plain list of names written before accounts
+// had products; such accounts become checking accounts without overdraft.
+func (service *Service) loadAccounts() error {
+	data, err := os.ReadFile(service.path(accountsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		data = []byte(`["` + MainAccount + `"]`)
+	} else if err != nil {
+		return errors.New("failed to read accounts file")
+	}
+	err = json.Unmarshal(data, &service.accounts)
+	if err == nil {
+		for i := range service.accounts {
+			if service.accounts[i].Currency == "" {
+				service.accounts[i].Currency = currency.Default
+			}
+		}
+		return nil
+	}
+	var names []string
+	err = json.Unmarshal(data, &names)
+	if err != nil {
+		return errors.New("failed to parse accounts file")
+	}
+	service.accounts = []Account{}
+	for _, name := range names {
+		service.accounts = append(service.accounts, Account{
+			Name:        name,
+			Currency:    currency.Default,
+			Product:     Product{Name: Checking},
+			LastAccrual: today(time.Now()),
+		})
+	}
+	return nil
+}
+
+func (service *Service) saveAccounts(accounts []Account) error {
+	data, err := json.MarshalIndent(accounts, "", "  ")
+	if err != nil {
+		return errors.New("failed to convert accounts to json")
+	}
+	return fileutils.WriteAtomic(service.path(accountsFile), data)
+}
+
+// record appends the event to the log and applies it to the state. A
+// snapshot is only there to speed up the next start, so failing to save one
+// is not an error.
+func (service *Service) record(event events.Event) error {
+	err := service.log.Append(&event)
+	if err != nil {
+		return err
+	}
+	service.state.Apply(event)
+	if event.Seq%snapshotInterval == 0 {
+		service.log.Snapshot(service.state)
+	}
+	return nil
+}
+
+func (service *Service) transactions() ([]ledger.Transaction, error) {
+	list, err := events.Load(service.dir)
+	if err != nil {
+		return nil, err
+	}
+	return events.Transactions(list), nil
+}
+
+// openLog reads the state from the event log, migrates data from before the
+// log and brings accounts.json and the balance files in line with it.
+func (service *Service) openLog() error {
+	var err error
+	service.log, service.state, err = events.Open(service.dir)
+	if err != nil {
+		return err
+	}
+	err = service.migrate()
+	if err != nil {
+		return err
+	}
+
+	missing := false
+	for _, opened := range service.state.Accounts {
+		if service.find(opened.Name) != nil {
+			continue
+		}
+		product := Product{Name: opened.Product}
+		index := slices.IndexFunc(service.products, func(product Product) bool {
+			return product.Name == opened.Product
+		})
+		if index >= 0 {
+			product = service.products[index]
+		}
+		service.accounts = append(service.accounts, Account{
+			Name:        opened.Name,
+			Currency:    opened.Currency,
+			Product:     product,
+			LastAccrual: today(time.Now()),
+		})
+		missing = true
+	}
+	if missing {
+		err = service.saveAccounts(service.accounts)
+		if err != nil {
+			return err
+		}
+	}
+
+	for account, balance := range service.state.Balances {
+		stored, err := fileutils.GetMoneyFromFile(service.balanceFile(account))
+		if err == nil && stored == balance {
+			continue
+		}
+		err = fileutils.WriteToFile(balance, service.balanceFile(account))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrate records accounts that are not in the event log yet, with the
+// balance in their balance file, and moves the ledger kept before the event
+// log into it. Transactions already in the log are skipped, so an
+// interrupted migration continues where it stopped; the old ledger is
+// renamed once it is done.
+func (service *Service) migrate() error {
+	history := service.path(historyFile)
+	_, err := os.Stat(history)
+	legacy := err == nil
+	transactions := []ledger.Transaction{}
+	if legacy {
+		err = ledger.Repair(history)
+		if err != nil {
+			return err
+		}
+		transactions, err = ledger.Load(history)
+		if err != nil {
+			return err
+		}
+	}
+	opened := time.Now()
+	if len(transactions) > 0 {
+		opened = transactions[0].Time
+	}
+	balances := ledger.Balances(transactions)
+
+	for _, account := range service.accounts {
+		if service.state.Opened(account.Name) {
+			continue
+		}
+		err = service.record(events.Opened(events.Account{
+			Name:     account.Name,
+			Currency: account.Currency,
+			Product:  account.Product.Name,
+		}, opened))
+		if err != nil {
+			return err
+		}
+		if _, ok := balances[account.Name]; ok {
+			continue
+		}
+		balance, err := fileutils.GetMoneyFromFile(service.balanceFile(account.Name))
+		if err != nil || balance == 0 {
+			continue
+		}
+		transaction := ledger.NewDeposit(account.Name, balance, balance, ledger.Details{Memo: "opening balance"})
+		if balance < 0 {
+			transaction = ledger.NewWithdrawal(account.Name, -balance, balance, ledger.Details{Memo: "opening balance"})
+		}
+		transaction.Time = opened
+		err = service.record(events.Recorded(transaction))
+		if err != nil {
+			return err
+		}
+	}
+	if !legacy {
+		return nil
+	}
+
+	recorded, err := service.transactions()
+	if err != nil {
+		return err
+	}
+	done := map[string]bool{}
+	for _, transaction := range recorded {
+		done[transactionKey(transaction)] = true
+	}
+	for _, transaction := range transactions {
+		if done[transactionKey(transaction)] {
+			continue
+		}
+		err = service.record(events.Recorded(transaction))
+		if err != nil {
+			return err
+		}
+	}
+	err = os.Rename(history, history+".migrated")
+	if err != nil {
+		return errors.New("failed to rename history file")
+	}
+	return nil
+}
+
+func transactionKey(transaction ledger.Transaction) string {
+	data, _ := json.Marshal(transaction)
+	return string(data)
+}
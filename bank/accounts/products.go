@@ -0,0 +1,176 @@
+package accounts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"time"
+
+	"example.com/bank/events"
+	"example.com/bank/ledger"
+	"example.com/bank/money"
+)
+
+const productsFile = "products.json"
+
+const (
+	Checking = "checking"
+	Savings  = "savings"
+)
+
+// Product holds the terms an account is opened with. InterestRate is a yearly
+// percentage accrued daily on positive balances and credited monthly.
+type Product struct {
+	Name           string      `json:"name"`
+	InterestRate   float64     `json:"interest_rate"`
+	OverdraftLimit money.Money `json:"overdraft_limit"`
+	OverdraftFee   money.Money `json:"overdraft_fee"`
+}
+
+// Account keeps the product terms it was opened with, so later changes to
+// products.json do not affect existing accounts. AccruedInterest is kept in
+// fractions of minor units until it is credited.
+type Account struct {
+	Name            string    `json:"name"`
+	Currency        string    `json:"currency"`
+	Product         Product   `json:"product"`
+	AccruedInterest float64   `json:"accrued_interest"`
+	LastAccrual     time.Time `json:"last_accrual"`
+}
+
+var defaultProducts = []Product{
+	{Name: Checking, OverdraftLimit: 50000, OverdraftFee: 2500},
+	{Name: Savings, InterestRate: 2.5},
+}
+
+func (service *Service) Products() []Product {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	return append([]Product{}, service.products...)
+}
+
+// AccrueInterest accrues interest for every full day since the last accrual
+// and credits it at the end of each month. Days the program was not running
+// are caught up using the end-of-day balances recorded in the ledger; only
+// the events since the oldest accrual are needed for them.
+func (service *Service) AccrueInterest(now time.Time) error {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	since := today(now)
+	for _, account := range service.accounts {
+		if account.Product.InterestRate > 0 && account.LastAccrual.Before(since) {
+			since = account.LastAccrual
+		}
+	}
+	if since.Equal(today(now)) {
+		return nil
+	}
+	list, err := events.Since(service.dir, since)
+	if err != nil {
+		return err
+	}
+	transactions := events.Transactions(list)
+	for i := range service.accounts {
+		err = service.accrue(&service.accounts[i], transactions, today(now))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (service *Service) accrue(account *Account, transactions []ledger.Transaction, today time.Time) error {
+	if account.Product.InterestRate <= 0 {
+		return nil
+	}
+	var credited money.Money
+	for account.LastAccrual.Before(today) {
+		day := account.LastAccrual
+		balance, ok := ledger.BalanceAt(transactions, account.Name, day.AddDate(0, 0, 1).Add(-time.Nanosecond))
+		if !ok {
+			balance = service.state.Balances[account.Name] - credited
+		}
+		balance += credited
+		if balance > 0 {
+			account.AccruedInterest += float64(balance) * account.Product.InterestRate / 100 / 365
+		}
+		account.LastAccrual = day.AddDate(0, 0, 1)
+		if account.LastAccrual.Month() == day.Month() {
+			continue
+		}
+
+		// The interest event is committed before the accrual is saved. If
+		// saving fails, the next run accrues the month again and finds the
+		// interest already in the ledger instead of crediting it twice.
+		interest := money.Money(math.Floor(account.AccruedInterest))
+		memo := fmt.Sprintf("interest for %s", day.Format("2006-01"))
+		if interest > 0 && !credits(transactions, account.Name, memo) {
+			balance = service.state.Balances[account.Name] + interest
+			_, err := service.commit(ledger.NewInterest(account.Name, interest, balance, memo))
+			if err != nil {
+				return err
+			}
+			credited += interest
+		}
+		account.AccruedInterest -= float64(interest)
+		err := service.saveAccounts(service.accounts)
+		if err != nil {
+			return err
+		}
+	}
+	return service.saveAccounts(service.accounts)
+}
+
+// credits reports whether the interest with the memo was already credited
+// to the account.
+func credits(transactions []ledger.Transaction, account, memo string) bool {
+	return slices.ContainsFunc(transactions, func(transaction ledger.Transaction) bool {
+		return transaction.Kind == ledger.Interest && transaction.Memo == memo &&
+			len(transaction.Postings) == 1 && transaction.Postings[0].Account == account
+	})
+}
+
+// covers reports whether amount can be taken from the account within its
+// overdraft limit. Going into the overdraft costs the overdraft fee, which
+// has to fit within the limit as well.
+func (service *Service) covers(account *Account, amount money.Money) bool {
+	balance := service.state.Balances[account.Name] - amount
+	if balance < 0 && account.Product.OverdraftFee > 0 {
+		balance -= account.Product.OverdraftFee
+	}
+	return balance >= -account.Product.OverdraftLimit
+}
+
+func (service *Service) chargeOverdraftFee(account *Account) error {
+	balance := service.state.Balances[account.Name]
+	if balance >= 0 || account.Product.OverdraftFee <= 0 {
+		return nil
+	}
+	balance -= account.Product.OverdraftFee
+	_, err := service.commit(ledger.NewFee(account.Name, account.Product.OverdraftFee, balance, "overdraft fee"))
+	return err
+}
+
+func (service *Service) loadProducts() error {
+	data, err := os.ReadFile(service.path(productsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		service.products = defaultProducts
+		return nil
+	}
+	if err != nil {
+		return errors.New("failed to read products file")
+	}
+	err = json.Unmarshal(data, &service.products)
+	if err != nil {
+		return errors.New("failed to parse products file")
+	}
+	return nil
+}
+
+func today(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+}
@@ -0,0 +1,72 @@
+package accounts
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"example.com/bank/ledger"
+	"example.com/bank/money"
+)
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, accountBalanceFile), []byte("0"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service, err := NewService(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = service.Open("checking", Checking, "EUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return service
+}
+
+// The default checking product allows 500.00 overdraft and charges 25.00
+// for going into it.
+func TestOverdraftFeeStaysWithinLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  money.Money
+		err     error
+		balance money.Money
+	}{
+		{"exactly the limit", 50000, ErrInsufficientFunds, 0},
+		{"limit less the fee", 47500, nil, -50000},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := newTestService(t)
+			_, err := service.Withdraw("checking", test.amount, ledger.Details{})
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Withdraw(%s) = %v, want %v", test.amount, err, test.err)
+			}
+			balance, _ := service.Balance("checking")
+			if balance != test.balance {
+				t.Errorf("balance = %s, want %s", balance, test.balance)
+			}
+		})
+	}
+}
+
+func TestTransferToExactLimitIsRefused(t *testing.T) {
+	service := newTestService(t)
+	_, err := service.Transfer("checking", MainAccount, 50000, ledger.Details{})
+	if !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("Transfer() = %v, want %v", err, ErrInsufficientFunds)
+	}
+	_, err = service.Transfer("checking", MainAccount, 47500, ledger.Details{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	balance, _ := service.Balance("checking")
+	if balance != -50000 {
+		t.Errorf("balance = %s, want -500.00", balance)
+	}
+}
@@ -0,0 +1,370 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"example.com/bank/accounts"
+	"example.com/bank/auth"
+	"example.com/bank/currency"
+	"example.com/bank/ledger"
+	"example.com/bank/money"
+)
+
+const (
+	pinHeader         = "X-Account-PIN"
+	idempotencyHeader = "Idempotency-Key"
+	dateLayout        = "2006-01-02"
+)
+
+// Server exposes an accounts.Service as a JSON REST API. Requests touching an
+// account must carry the account PIN in the X-Account-PIN header.
+type Server struct {
+	service         *accounts.Service
+	credentialsFile string
+
+	credentialsMu sync.Mutex
+	credentials   auth.Store
+
+	idempotencyMu sync.Mutex
+	responses     map[string]*storedResponse
+}
+
+// storedResponse is the outcome of the first request with an Idempotency-Key.
+// Its own lock makes retries with the same key wait for the first request to
+// finish, while requests with other keys go ahead.
+type storedResponse struct {
+	mu          sync.Mutex
+	done        bool
+	fingerprint [sha256.Size]byte
+	status      int
+	body        []byte
+}
+
+type transactionRequest struct {
+	Kind   string      `json:"kind"`
+	Amount money.Money `json:"amount"`
+	ledger.Details
+}
+
+type transferRequest struct {
+	From   string      `json:"from"`
+	To     string      `json:"to"`
+	Amount money.Money `json:"amount"`
+	ledger.Details
+}
+
+type openAccountRequest struct {
+	Name     string `json:"name"`
+	Product  string `json:"product"`
+	Currency string `json:"currency"`
+	PIN      string `json:"pin"`
+}
+
+type balanceResponse struct {
+	Account string      `json:"account"`
+	Balance money.Money `json:"balance"`
+}
+
+func New(service *accounts.Service, credentials auth.Store, credentialsFile string) *Server {
+	return &Server{
+		service:         service,
+		credentials:     credentials,
+		credentialsFile: credentialsFile,
+		responses:       make(map[string]*storedResponse),
+	}
+}
+
+func (server *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /accounts", server.listAccounts)
+	mux.HandleFunc("POST /accounts", server.idempotent(server.openAccount))
+	mux.HandleFunc("GET /accounts/{id}/balance", server.authorized(server.balance))
+	mux.HandleFunc("GET /accounts/{id}/transactions", server.authorized(server.history))
+	mux.HandleFunc("POST /accounts/{id}/transactions", server.authorized(server.idempotent(server.postTransaction)))
+	mux.HandleFunc("POST /transfers", server.payerAuthorized(server.idempotent(server.transfer)))
+	return mux
+}
+
+func (server *Server) listAccounts(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, server.service.Accounts())
+}
+
+func (server *Server) openAccount(w http.ResponseWriter, r *http.Request) {
+	var request openAccountRequest
+	if !readJSON(w, r, &request) {
+		return
+	}
+	server.credentialsMu.Lock()
+	defer server.credentialsMu.Unlock()
+
+	candidate := auth.Store{}
+	err := candidate.SetPIN(request.Name, request.PIN)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	err = server.service.Open(request.Name, request.Product, request.Currency)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	server.credentials[request.Name] = candidate[request.Name]
+	err = server.credentials.Save(server.credentialsFile)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, balanceResponse{Account: request.Name})
+}
+
+func (server *Server) balance(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	balance, err := server.service.Balance(id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, balanceResponse{Account: id, Balance: balance})
+}
+
+func (server *Server) history(w http.ResponseWriter, r *http.Request) {
+	from, err := parseDate(r.URL.Query().Get("from"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	to, err := parseDate(r.URL.Query().Get("to"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if !to.IsZero() {
+		to = to.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	transactions, err := server.service.History(r.PathValue("id"), from, to)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, transactions)
+}
+
+func (server *Server) postTransaction(w http.ResponseWriter, r *http.Request) {
+	var request transactionRequest
+	if !readJSON(w, r, &request) {
+		return
+	}
+	var transaction ledger.Transaction
+	var err error
+	switch request.Kind {
+	case ledger.Deposit:
+		transaction, err = server.service.Deposit(r.PathValue("id"), request.Amount, request.Details)
+	case ledger.Withdrawal:
+		transaction, err = server.service.Withdraw(r.PathValue("id"), request.Amount, request.Details)
+	default:
+		writeError(w, http.StatusBadRequest, errors.New("kind must be deposit or withdrawal"))
+		return
+	}
+	if err != nil && !errors.Is(err, accounts.ErrBalanceNotSaved) {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, transaction)
+}
+
+func (server *Server) transfer(w http.ResponseWriter, r *http.Request) {
+	var request transferRequest
+	if !readJSON(w, r, &request) {
+		return
+	}
+	transaction, err := server.service.Transfer(request.From, request.To, request.Amount, request.Details)
+	if err != nil && !errors.Is(err, accounts.ErrBalanceNotSaved) {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, transaction)
+}
+
+func (server *Server) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if server.checkPIN(w, r, r.PathValue("id")) {
+			next(w, r)
+		}
+	}
+}
+
+// payerAuthorized checks the PIN of the account a transfer is made from. Like
+// authorized it runs before the idempotency check, so a stored response is
+// only ever replayed to a caller who knows the PIN.
+func (server *Server) payerAuthorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, errors.New("failed to read request body"))
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		var request transferRequest
+		if !readJSON(w, r, &request) {
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		if server.checkPIN(w, r, request.From) {
+			next(w, r)
+		}
+	}
+}
+
+func (server *Server) checkPIN(w http.ResponseWriter, r *http.Request, id string) bool {
+	if !server.service.Exists(id) {
+		writeServiceError(w, accounts.ErrUnknownAccount)
+		return false
+	}
+	server.credentialsMu.Lock()
+	defer server.credentialsMu.Unlock()
+
+	err := server.credentials.Verify(id, r.Header.Get(pinHeader), time.Now())
+	saveErr := server.credentials.Save(server.credentialsFile)
+	if err == nil && saveErr != nil {
+		err = saveErr
+	}
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, auth.ErrLocked):
+		writeError(w, http.StatusLocked, err)
+	case errors.Is(err, auth.ErrWrongPIN), errors.Is(err, auth.ErrNoPIN):
+		writeError(w, http.StatusUnauthorized, err)
+	default:
+		writeError(w, http.StatusInternalServerError, err)
+	}
+	return false
+}
+
+// idempotent replays the stored response when a request is retried with the
+// same Idempotency-Key, so a retried POST never posts a transaction twice.
+// Keys are kept in memory for the lifetime of the server.
+func (server *Server) idempotent(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyHeader)
+		if key == "" {
+			next(w, r)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, errors.New("failed to read request body"))
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		fingerprint := sha256.Sum256(body)
+		key = r.Method + " " + r.URL.Path + " " + key
+
+		server.idempotencyMu.Lock()
+		stored, ok := server.responses[key]
+		if !ok {
+			stored = &storedResponse{}
+			server.responses[key] = stored
+		}
+		server.idempotencyMu.Unlock()
+
+		stored.mu.Lock()
+		defer stored.mu.Unlock()
+
+		if stored.done {
+			if stored.fingerprint != fingerprint {
+				writeError(w, http.StatusUnprocessableEntity, errors.New("idempotency key was used for a different request"))
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(stored.status)
+			w.Write(stored.body)
+			return
+		}
+
+		// Failed logins and server errors are not stored, so a retry with the
+		// right PIN or after the failure is handled afresh.
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next(recorder, r)
+		if recorder.status < http.StatusInternalServerError &&
+			recorder.status != http.StatusUnauthorized && recorder.status != http.StatusLocked {
+			stored.done = true
+			stored.fingerprint = fingerprint
+			stored.status = recorder.status
+			stored.body = recorder.body.Bytes()
+		}
+	}
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (recorder *responseRecorder) WriteHeader(status int) {
+	recorder.status = status
+	recorder.ResponseWriter.WriteHeader(status)
+}
+
+func (recorder *responseRecorder) Write(data []byte) (int, error) {
+	recorder.body.Write(data)
+	return recorder.ResponseWriter.Write(data)
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, target any) bool {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(target)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errors.New("invalid request body"))
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func writeServiceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, accounts.ErrUnknownAccount):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, accounts.ErrInsufficientFunds), errors.Is(err, accounts.ErrAccountExists):
+		writeError(w, http.StatusConflict, err)
+	case errors.Is(err, accounts.ErrInvalidAmount),
+		errors.Is(err, accounts.ErrInvalidName),
+		errors.Is(err, accounts.ErrUnknownProduct),
+		errors.Is(err, currency.ErrUnknownCurrency),
+		errors.Is(err, accounts.ErrSameAccount):
+		writeError(w, http.StatusBadRequest, err)
+	default:
+		writeError(w, http.StatusInternalServerError, err)
+	}
+}
+
+func parseDate(text string) (time.Time, error) {
+	if text == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.ParseInLocation(dateLayout, text, time.Local)
+	if err != nil {
+		return time.Time{}, errors.New("dates must be formatted as YYYY-MM-DD")
+	}
+	return date, nil
+}
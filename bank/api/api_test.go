@@ -0,0 +1,225 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"example.com/bank/accounts"
+	"example.com/bank/auth"
+	"example.com/bank/money"
+)
+
+const (
+	mainPIN = "1234"
+	bobPIN  = "5678"
+)
+
+// newTestServer serves a bank in a temporary directory with the main account
+// and a checking account "bob", both with a PIN.
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "balance.txt"), []byte("0"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service, err := accounts.NewService(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = service.Open("bob", accounts.Checking, "EUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	credentials := auth.Store{}
+	for account, pin := range map[string]string{accounts.MainAccount: mainPIN, "bob": bobPIN} {
+		err = credentials.SetPIN(account, pin)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	server := httptest.NewServer(New(service, credentials, filepath.Join(dir, "credentials.json")).Handler())
+	t.Cleanup(server.Close)
+	return server
+}
+
+type call struct {
+	method string
+	path   string
+	pin    string
+	key    string
+	body   string
+}
+
+func (c call) do(t *testing.T, server *httptest.Server) *http.Response {
+	t.Helper()
+	request, err := http.NewRequest(c.method, server.URL+c.path, bytes.NewBufferString(c.body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.pin != "" {
+		request.Header.Set(pinHeader, c.pin)
+	}
+	if c.key != "" {
+		request.Header.Set(idempotencyHeader, c.key)
+	}
+	response, err := server.Client().Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { response.Body.Close() })
+	return response
+}
+
+func balanceOf(t *testing.T, server *httptest.Server, account, pin string) money.Money {
+	t.Helper()
+	response := call{method: "GET", path: "/accounts/" + account + "/balance", pin: pin}.do(t, server)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("balance of %s: status %d", account, response.StatusCode)
+	}
+	var balance balanceResponse
+	err := json.NewDecoder(response.Body).Decode(&balance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return balance.Balance
+}
+
+// TestAuthentication stays below the three failed attempts per account that
+// lock it.
+func TestAuthentication(t *testing.T) {
+	server := newTestServer(t)
+	tests := []struct {
+		name   string
+		call   call
+		status int
+	}{
+		{"no PIN", call{method: "GET", path: "/accounts/main/balance"}, http.StatusUnauthorized},
+		{"wrong PIN", call{method: "GET", path: "/accounts/main/balance", pin: "0000"}, http.StatusUnauthorized},
+		{"other account's PIN", call{method: "GET", path: "/accounts/bob/balance", pin: mainPIN}, http.StatusUnauthorized},
+		{"right PIN", call{method: "GET", path: "/accounts/main/balance", pin: mainPIN}, http.StatusOK},
+		{"unknown account", call{method: "GET", path: "/accounts/nobody/balance", pin: mainPIN}, http.StatusNotFound},
+		{"transfer without PIN", call{method: "POST", path: "/transfers", body: `{"from":"bob","to":"main","amount":1}`}, http.StatusUnauthorized},
+		{"list accounts is public", call{method: "GET", path: "/accounts"}, http.StatusOK},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response := test.call.do(t, server)
+			if response.StatusCode != test.status {
+				t.Errorf("status = %d, want %d", response.StatusCode, test.status)
+			}
+		})
+	}
+}
+
+func TestTransactionRoundTrip(t *testing.T) {
+	server := newTestServer(t)
+	steps := []struct {
+		name string
+		call call
+	}{
+		{"deposit", call{method: "POST", path: "/accounts/main/transactions", pin: mainPIN, body: `{"kind":"deposit","amount":100}`}},
+		{"withdraw", call{method: "POST", path: "/accounts/main/transactions", pin: mainPIN, body: `{"kind":"withdrawal","amount":"30.50"}`}},
+		{"transfer", call{method: "POST", path: "/transfers", pin: mainPIN, body: `{"from":"main","to":"bob","amount":20}`}},
+	}
+	for _, step := range steps {
+		response := step.call.do(t, server)
+		if response.StatusCode != http.StatusCreated {
+			body, _ := io.ReadAll(response.Body)
+			t.Fatalf("%s: status %d: %s", step.name, response.StatusCode, body)
+		}
+	}
+	if balance := balanceOf(t, server, "main", mainPIN); balance != 4950 {
+		t.Errorf("main balance = %s, want 49.50", balance)
+	}
+	if balance := balanceOf(t, server, "bob", bobPIN); balance != 2000 {
+		t.Errorf("bob balance = %s, want 20.00", balance)
+	}
+}
+
+func TestInvalidRequests(t *testing.T) {
+	server := newTestServer(t)
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"insufficient funds", `{"kind":"withdrawal","amount":10}`, http.StatusConflict},
+		{"three decimal places", `{"kind":"deposit","amount":10.005}`, http.StatusBadRequest},
+		{"unknown kind", `{"kind":"gift","amount":10}`, http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response := call{method: "POST", path: "/accounts/main/transactions", pin: mainPIN, body: test.body}.do(t, server)
+			if response.StatusCode != test.status {
+				t.Errorf("status = %d, want %d", response.StatusCode, test.status)
+			}
+		})
+	}
+	if balance := balanceOf(t, server, "main", mainPIN); balance != 0 {
+		t.Errorf("balance = %s after rejected requests, want 0.00", balance)
+	}
+}
+
+func TestIdempotencyKeyReplaysResponse(t *testing.T) {
+	server := newTestServer(t)
+	deposit := call{method: "POST", path: "/accounts/main/transactions", pin: mainPIN, key: "retry-1", body: `{"kind":"deposit","amount":25}`}
+
+	first := deposit.do(t, server)
+	firstBody, _ := io.ReadAll(first.Body)
+	second := deposit.do(t, server)
+	secondBody, _ := io.ReadAll(second.Body)
+
+	if first.StatusCode != http.StatusCreated || second.StatusCode != http.StatusCreated {
+		t.Fatalf("statuses = %d, %d, want %d", first.StatusCode, second.StatusCode, http.StatusCreated)
+	}
+	if second.Header.Get("Idempotent-Replayed") != "true" {
+		t.Error("retry was not marked as replayed")
+	}
+	if !bytes.Equal(firstBody, secondBody) {
+		t.Errorf("replayed body differs:\n%s\n%s", firstBody, secondBody)
+	}
+	if balance := balanceOf(t, server, "main", mainPIN); balance != 2500 {
+		t.Errorf("balance = %s, want 25.00 posted once", balance)
+	}
+
+	reused := deposit
+	reused.body = `{"kind":"deposit","amount":99}`
+	if response := reused.do(t, server); response.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("key reused for another body: status = %d, want %d", response.StatusCode, http.StatusUnprocessableEntity)
+	}
+}
+
+func TestIdempotentTransferChecksPINFirst(t *testing.T) {
+	server := newTestServer(t)
+	call{method: "POST", path: "/accounts/main/transactions", pin: mainPIN, body: `{"kind":"deposit","amount":50}`}.do(t, server)
+	transfer := call{method: "POST", path: "/transfers", key: "transfer-1", body: `{"from":"main","to":"bob","amount":10}`}
+
+	wrongPIN := transfer
+	wrongPIN.pin = "0000"
+	if response := wrongPIN.do(t, server); response.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("wrong PIN: status = %d, want %d", response.StatusCode, http.StatusUnauthorized)
+	}
+	rightPIN := transfer
+	rightPIN.pin = mainPIN
+	response := rightPIN.do(t, server)
+	if response.StatusCode != http.StatusCreated || response.Header.Get("Idempotent-Replayed") != "" {
+		t.Fatalf("right PIN after a failed attempt: status = %d, replayed %q", response.StatusCode, response.Header.Get("Idempotent-Replayed"))
+	}
+	for _, pin := range []string{"", "0000"} {
+		retry := transfer
+		retry.pin = pin
+		if response := retry.do(t, server); response.StatusCode != http.StatusUnauthorized {
+			t.Errorf("retry with PIN %q: status = %d, want %d", pin, response.StatusCode, http.StatusUnauthorized)
+		}
+	}
+	if balance := balanceOf(t, server, "bob", bobPIN); balance != 1000 {
+		t.Errorf("bob balance = %s, want 10.00 transferred once", balance)
+	}
+}
@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+
+	"example.com/bank/fileutils"
+)
+
+const (
+	iterations    = 600_000
+	keyLength     = 32
+	saltLength    = 16
+	maxAttempts   = 3
+	lockoutPeriod = 5 * time.Minute
+	minPINLength  = 4
+	maxPINLength  = 12
+)
+
+var (
+	ErrWrongPIN = errors.New("wrong PIN")
+	ErrLocked   = errors.New("account is locked after too many failed attempts")
+	ErrNoPIN    = errors.New("account has no PIN")
+)
+
+type Credential struct {
+	Salt           []byte    `json:"salt"`
+	Hash           []byte    `json:"hash"`
+	Iterations     int       `json:"iterations"`
+	FailedAttempts int       `json:"failed_attempts"`
+	LockedUntil    time.Time `json:"locked_until"`
+}
+
+// Store holds the credentials of every account, keyed by account name. It is
+// kept in its own file so balances never sit next to PIN hashes.
+type Store map[string]Credential
+
+func Load(fileName string) (Store, error) {
+	data, err := os.ReadFile(fileName)
+	if errors.Is(err, os.ErrNotExist) {
+		return Store{}, nil
+	}
+	if err != nil {
+		return nil, errors.New("failed to read credentials file")
+	}
+	store := Store{}
+	err = json.Unmarshal(data, &store)
+	if err != nil {
+		return nil, errors.New("failed to parse credentials file")
+	}
+	return store, nil
+}
+
+func (store Store) Save(fileName string) error {
+	data, err := json.Marshal(store)
+	if err != nil {
+		return errors.New("failed to convert credentials to json")
+	}
+	return fileutils.WriteAtomicMode(fileName, data, 0600)
+}
+
+func (store Store) HasPIN(account string) bool {
+	_, ok := store[account]
+	return ok
+}
+
+func (store Store) SetPIN(account, pin string) error {
+	err := validatePIN(pin)
+	if err != nil {
+		return err
+	}
+	salt := make([]byte, saltLength)
+	_, err = rand.Read(salt)
+	if err != nil {
+		return errors.New("failed to generate salt")
+	}
+	hash, err := derive(pin, salt, iterations)
+	if err != nil {
+		return err
+	}
+	store[account] = Credential{
+		Salt:       salt,
+		Hash:       hash,
+		Iterations: iterations,
+	}
+	return nil
+}
+
+// Verify checks the PIN and records the attempt. After maxAttempts failures
+// in a row the account is locked for lockoutPeriod.
+func (store Store) Verify(account, pin string, now time.Time) error {
+	credential, ok := store[account]
+	if !ok {
+		return ErrNoPIN
+	}
+	if now.Before(credential.LockedUntil) {
+		return ErrLocked
+	}
+	hash, err := derive(pin, credential.Salt, credential.Iterations)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(hash, credential.Hash) != 1 {
+		credential.FailedAttempts++
+		if credential.FailedAttempts >= maxAttempts {
+			credential.FailedAttempts = 0
+			credential.LockedUntil = now.Add(lockoutPeriod)
+			store[account] = credential
+			return ErrLocked
+		}
+		store[account] = credential
+		return ErrWrongPIN
+	}
+	credential.FailedAttempts = 0
+	credential.LockedUntil = time.Time{}
+	store[account] = credential
+	return nil
+}
+
+func derive(pin string, salt []byte, iterations int) ([]byte, error) {
+	hash, err := pbkdf2.Key(sha256.New, pin, salt, iterations, keyLength)
+	if err != nil {
+		return nil, errors.New("failed to derive PIN hash")
+	}
+	return hash, nil
+}
+
+func validatePIN(pin string) error {
+	if len(pin) < minPINLength || len(pin) > maxPINLength {
+		return errors.New("PIN must have between 4 and 12 digits")
+	}
+	for _, digit := range pin {
+		if digit < '0' || digit > '9' {
+			return errors.New("PIN may only contain digits")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package budget
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"example.com/bank/ledger"
+	"example.com/bank/messages"
+	"example.com/bank/money"
+)
+
+const Uncategorized = "uncategorized"
+
+const monthLayout = "2006-01"
+const barWidth = 40
+
+// Budgets holds the monthly spending limit of each category, per account.
+type Budgets map[string]map[string]money.Money
+
+func Load(fileName string) (Budgets, error) {
+	data, err := os.ReadFile(fileName)
+	if errors.Is(err, os.ErrNotExist) {
+		return Budgets{}, nil
+	}
+	if err != nil {
+		return nil, errors.New("failed to read budgets file")
+	}
+	budgets := Budgets{}
+	err = json.Unmarshal(data, &budgets)
+	if err != nil {
+		return nil, errors.New("failed to parse budgets file")
+	}
+	return budgets, nil
+}
+
+func (budgets Budgets) Save(fileName string) error {
+	data, err := json.MarshalIndent(budgets, "", "  ")
+	if err != nil {
+		return errors.New("failed to convert budgets to json")
+	}
+	return os.WriteFile(fileName, data, 0644)
+}
+
+// Set changes the monthly limit of a category; a limit of 0 removes it.
+func (budgets Budgets) Set(account, category string, limit money.Money) {
+	category = Category(category)
+	if limit <= 0 {
+		delete(budgets[account], category)
+		return
+	}
+	if budgets[account] == nil {
+		budgets[account] = map[string]money.Money{}
+	}
+	budgets[account][category] = limit
+}
+
+func (budgets Budgets) Limit(account, category string) (money.Money, bool) {
+	limit, ok := budgets[account][Category(category)]
+	return limit, ok
+}
+
+// Category normalizes a category name so "Groceries " and "groceries" are
+// the same budget line.
+func Category(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return Uncategorized
+	}
+	return name
+}
+
+func MonthOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+// Spending adds up the money that left the account during the month, per
+// category. Transfers only move money to another of the bank's own accounts,
+// so they are not spending.
+func Spending(transactions []ledger.Transaction, account string, month time.Time) map[string]money.Money {
+	month = MonthOf(month)
+	spending := map[string]money.Money{}
+	for _, transaction := range transactions {
+		posting, ok := transaction.PostingFor(account)
+		if !ok || posting.Amount >= 0 || !MonthOf(transaction.Time).Equal(month) {
+			continue
+		}
+		if transaction.Kind == ledger.Transfer {
+			continue
+		}
+		spending[Category(transaction.Category)] -= posting.Amount
+	}
+	return spending
+}
+
+// WriteReport prints the spending per category for each month as a table
+// followed by a bar chart.
+func WriteReport(w io.Writer, transactions []ledger.Transaction, account string, months []time.Time, budgets Budgets) error {
+	spending := make([]map[string]money.Money, len(months))
+	categories := []string{}
+	var largest money.Money
+	for i, month := range months {
+		spending[i] = Spending(transactions, account, month)
+		for category, amount := range spending[i] {
+			if !slices.Contains(categories, category) {
+				categories = append(categories, category)
+			}
+			largest = max(largest, amount)
+		}
+	}
+	for category := range budgets[account] {
+		if !slices.Contains(categories, category) {
+			categories = append(categories, category)
+		}
+	}
+	slices.Sort(categories)
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("%-16s", messages.T("Category")))
+	for _, month := range months {
+		builder.WriteString(fmt.Sprintf("  %10s", month.Format(monthLayout)))
+	}
+	builder.WriteString(fmt.Sprintf("  %10s\n", messages.T("Budget")))
+	builder.WriteString(strings.Repeat("-", 16+12*(len(months)+1)) + "\n")
+
+	totals := make([]money.Money, len(months))
+	for _, category := range categories {
+		builder.WriteString(fmt.Sprintf("%-16s", category))
+		for i := range months {
+			builder.WriteString(fmt.Sprintf("  %10s", spending[i][category]))
+			totals[i] += spending[i][category]
+		}
+		limit, ok := budgets.Limit(account, category)
+		if ok {
+			builder.WriteString(fmt.Sprintf("  %10s\n", limit))
+		} else {
+			builder.WriteString(fmt.Sprintf("  %10s\n", "-"))
+		}
+	}
+	builder.WriteString(fmt.Sprintf("%-16s", messages.T("Total")))
+	for _, total := range totals {
+		builder.WriteString(fmt.Sprintf("  %10s", total))
+	}
+	builder.WriteString("\n")
+
+	for i, month := range months {
+		builder.WriteString("\n" + month.Format(monthLayout) + "\n")
+		for _, category := range categories {
+			amount := spending[i][category]
+			if amount == 0 {
+				continue
+			}
+			marker := ""
+			if limit, ok := budgets.Limit(account, category); ok && amount > limit {
+				marker = " " + messages.T("over budget")
+			}
+			builder.WriteString(fmt.Sprintf("  %-16s %-*s %s%s\n",
+				category, barWidth, strings.Repeat("#", bar(amount, largest)), amount, marker))
+		}
+	}
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+func bar(amount, largest money.Money) int {
+	if largest <= 0 {
+		return 0
+	}
+	return max(1, int(int64(amount)*barWidth/int64(largest)))
+}
@@ -0,0 +1,29 @@
+package budget
+
+import (
+	"testing"
+	"time"
+
+	"example.com/bank/ledger"
+	"example.com/bank/money"
+)
+
+func TestSpendingSkipsTransfersBetweenOwnAccounts(t *testing.T) {
+	groceries := ledger.Details{Category: "Groceries"}
+	transactions := []ledger.Transaction{
+		ledger.NewWithdrawal("main", 3000, 7000, groceries),
+		ledger.NewTransfer("main", "savings", 5000, 2000, 5000, groceries),
+		ledger.NewFee("main", 500, 1500, "overdraft fee"),
+		ledger.NewDeposit("main", 1000, 2500, groceries),
+	}
+	spending := Spending(transactions, "main", time.Now())
+	want := map[string]money.Money{"groceries": 3000, ledger.Fee: 500}
+	if len(spending) != len(want) {
+		t.Errorf("Spending() = %v, want %v", spending, want)
+	}
+	for category, amount := range want {
+		if spending[category] != amount {
+			t.Errorf("spending on %s = %s, want %s", category, spending[category], amount)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+)
+
+// Config holds the branding and contact details shown to customers.
+type Config struct {
+	Name     string `json:"name"`
+	Phone    string `json:"phone,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Hours    string `json:"hours,omitempty"`
+	Address  string `json:"address,omitempty"`
+	Language string `json:"language,omitempty"`
+}
+
+func Default() Config {
+	return Config{Name: "Go Bank"}
+}
+
+// Load reads the config file. Without a file, or for fields left out of it,
+// the defaults are used.
+func Load(fileName string) (Config, error) {
+	config := Default()
+	data, err := os.ReadFile(fileName)
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return Config{}, errors.New("failed to read config file")
+	}
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		return Config{}, errors.New("failed to parse config file")
+	}
+	if config.Name == "" {
+		config.Name = Default().Name
+	}
+	return config, nil
+}
@@ -0,0 +1,119 @@
+package currency
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"slices"
+	"strings"
+
+	"example.com/bank/money"
+)
+
+const Default = "EUR"
+
+var ErrUnknownCurrency = errors.New("currency is not in the exchange rate table")
+
+// Table is the local exchange rate table. Rates hold how many units of each
+// currency one unit of Base buys. FeePercent is charged on every conversion.
+type Table struct {
+	Base       string             `json:"base"`
+	Rates      map[string]float64 `json:"rates"`
+	FeePercent float64            `json:"fee_percent"`
+}
+
+func Load(fileName string) (Table, error) {
+	data, err := os.ReadFile(fileName)
+	if errors.Is(err, os.ErrNotExist) {
+		return Table{Base: Default, Rates: map[string]float64{}}, nil
+	}
+	if err != nil {
+		return Table{}, errors.New("failed to read exchange rates file")
+	}
+	var table Table
+	err = json.Unmarshal(data, &table)
+	if err != nil {
+		return Table{}, errors.New("failed to parse exchange rates file")
+	}
+	table.Base = strings.ToUpper(table.Base)
+	if table.Base == "" {
+		table.Base = Default
+	}
+	rates := make(map[string]float64)
+	for code, rate := range table.Rates {
+		if rate <= 0 {
+			return Table{}, errors.New("exchange rates must be greater then 0")
+		}
+		rates[strings.ToUpper(code)] = rate
+	}
+	table.Rates = rates
+	if table.FeePercent < 0 || table.FeePercent >= 100 {
+		return Table{}, errors.New("exchange fee must be at least 0 and below 100 percent")
+	}
+	return table, nil
+}
+
+func (table Table) Currencies() []string {
+	codes := []string{table.Base}
+	for code := range table.Rates {
+		if code != table.Base {
+			codes = append(codes, code)
+		}
+	}
+	slices.Sort(codes[1:])
+	return codes
+}
+
+func (table Table) Known(code string) bool {
+	return slices.Contains(table.Currencies(), code)
+}
+
+// Rate returns how many units of to one unit of from buys.
+func (table Table) Rate(from, to string) (float64, error) {
+	fromRate, err := table.perBase(from)
+	if err != nil {
+		return 0, err
+	}
+	toRate, err := table.perBase(to)
+	if err != nil {
+		return 0, err
+	}
+	return toRate / fromRate, nil
+}
+
+// Convert exchanges amount from one currency to another. The fee is taken in
+// the source currency before converting; no fee is charged when both
+// currencies are the same.
+func (table Table) Convert(amount money.Money, from, to string) (converted money.Money, rate float64, fee money.Money, err error) {
+	if from == to {
+		return amount, 1, 0, nil
+	}
+	rate, err = table.Rate(from, to)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	fee = money.Money(math.Round(float64(amount) * table.FeePercent / 100))
+	converted = money.Money(math.Round(float64(amount-fee) * rate))
+	return converted, rate, fee, nil
+}
+
+// Value converts amount at the current rate without a fee, for reporting.
+func (table Table) Value(amount money.Money, from, to string) (money.Money, error) {
+	rate, err := table.Rate(from, to)
+	if err != nil {
+		return 0, err
+	}
+	return money.Money(math.Round(float64(amount) * rate)), nil
+}
+
+func (table Table) perBase(code string) (float64, error) {
+	if code == table.Base {
+		return 1, nil
+	}
+	rate, ok := table.Rates[code]
+	if !ok {
+		return 0, ErrUnknownCurrency
+	}
+	return rate, nil
+}
@@ -0,0 +1,83 @@
+package events
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"example.com/bank/ledger"
+)
+
+const (
+	AccountOpened   = "AccountOpened"
+	Deposited       = "Deposited"
+	Withdrawn       = "Withdrawn"
+	Transferred     = "Transferred"
+	InterestAccrued = "InterestAccrued"
+)
+
+var (
+	ErrTampered  = errors.New("event log was modified")
+	ErrTruncated = errors.New("event log is truncated")
+)
+
+// Account is what AccountOpened records about a new account.
+type Account struct {
+	Name     string `json:"name"`
+	Currency string `json:"currency"`
+	Product  string `json:"product"`
+}
+
+// Event is one entry of the log. Every event carries the hash of the one
+// before it, so changing, removing or reordering an entry breaks the chain.
+type Event struct {
+	Seq         int64               `json:"seq"`
+	Time        time.Time           `json:"time"`
+	Type        string              `json:"type"`
+	Account     *Account            `json:"account,omitempty"`
+	Transaction *ledger.Transaction `json:"transaction,omitempty"`
+	Prev        string              `json:"prev"`
+	Hash        string              `json:"hash"`
+}
+
+func Opened(account Account, t time.Time) Event {
+	return Event{Time: t, Type: AccountOpened, Account: &account}
+}
+
+// Recorded wraps a ledger transaction in the event matching its kind. Fees
+// are withdrawals.
+func Recorded(transaction ledger.Transaction) Event {
+	kind := Withdrawn
+	switch transaction.Kind {
+	case ledger.Deposit:
+		kind = Deposited
+	case ledger.Transfer:
+		kind = Transferred
+	case ledger.Interest:
+		kind = InterestAccrued
+	}
+	return Event{Time: transaction.Time, Type: kind, Transaction: &transaction}
+}
+
+// Transactions returns the ledger transactions recorded by the events.
+func Transactions(events []Event) []ledger.Transaction {
+	transactions := []ledger.Transaction{}
+	for _, event := range events {
+		if event.Transaction != nil {
+			transactions = append(transactions, *event.Transaction)
+		}
+	}
+	return transactions
+}
+
+func (event Event) digest() (string, error) {
+	event.Hash = ""
+	data, err := json.Marshal(event)
+	if err != nil {
+		return "", errors.New("failed to convert event to json")
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,305 @@
+package events
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"example.com/bank/fileutils"
+)
+
+const (
+	logFile      = "events.jsonl"
+	headFile     = "events.head"
+	snapshotFile = "snapshot.json"
+)
+
+// Log appends events to events.jsonl in a directory. After every append the
+// number and hash of the last event are written to events.head, so a log
+// that was cut short after the last event is detected as well.
+type Log struct {
+	dir    string
+	seq    int64
+	hash   string
+	offset int64
+}
+
+type head struct {
+	Seq  int64  `json:"seq"`
+	Hash string `json:"hash"`
+}
+
+// Open checks the log and returns it with the current state. When the
+// latest snapshot fits the log, only the events after it are read; Verify
+// checks the snapshot itself.
+func Open(dir string) (*Log, State, error) {
+	log := &Log{dir: dir}
+	err := log.repair()
+	if err != nil {
+		return nil, State{}, err
+	}
+	saved, ok := loadSnapshot(log.path(snapshotFile))
+	if ok {
+		state, err := log.open(saved.Offset, saved.State)
+		if err == nil {
+			return log, state, nil
+		}
+	}
+	state, err := log.open(0, NewState())
+	if err != nil {
+		return nil, State{}, err
+	}
+	return log, state, nil
+}
+
+// Load reads and checks all events.
+func Load(dir string) ([]Event, error) {
+	log := &Log{dir: dir}
+	events, _, err := log.read(0, 0, "")
+	if err != nil {
+		return nil, err
+	}
+	var last Event
+	if len(events) > 0 {
+		last = events[len(events)-1]
+	}
+	_, err = log.checkHead(events, last.Seq, last.Hash)
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// Since reads and checks the events from the latest snapshot on, which
+// holds all events after since when the snapshot is not newer. Otherwise,
+// or when the snapshot does not fit the log, all events are read.
+func Since(dir string, since time.Time) ([]Event, error) {
+	log := &Log{dir: dir}
+	saved, ok := loadSnapshot(log.path(snapshotFile))
+	if ok && !saved.State.Time.After(since) {
+		events, _, err := log.read(saved.Offset, saved.State.Seq, saved.State.Hash)
+		if err == nil {
+			last := Event{Seq: saved.State.Seq, Hash: saved.State.Hash}
+			if len(events) > 0 {
+				last = events[len(events)-1]
+			}
+			_, err = log.checkHead(events, last.Seq, last.Hash)
+		}
+		if err == nil {
+			return events, nil
+		}
+	}
+	return Load(dir)
+}
+
+// Verify checks the whole log and that the snapshot matches the state the
+// events add up to. It returns the number of events.
+func Verify(dir string) (int, error) {
+	events, err := Load(dir)
+	if err != nil {
+		return 0, err
+	}
+	log := &Log{dir: dir}
+	saved, ok := loadSnapshot(log.path(snapshotFile))
+	if !ok {
+		return len(events), nil
+	}
+	state := NewState()
+	var offset int64
+	for _, event := range events {
+		if event.Seq > saved.State.Seq {
+			break
+		}
+		state.Apply(event)
+		line, _ := json.Marshal(event)
+		offset += int64(len(line)) + 1
+	}
+	if state.Seq != saved.State.Seq || offset != saved.Offset ||
+		!reflect.DeepEqual(state.Accounts, saved.State.Accounts) ||
+		!reflect.DeepEqual(state.Balances, saved.State.Balances) {
+		return len(events), fmt.Errorf("snapshot: %w", ErrTampered)
+	}
+	return len(events), nil
+}
+
+func (log *Log) Seq() int64 {
+	return log.seq
+}
+
+// Append chains the event to the last one and writes it as a single line,
+// synced to disk before returning.
+func (log *Log) Append(event *Event) error {
+	event.Seq = log.seq + 1
+	event.Prev = log.hash
+	hash, err := event.digest()
+	if err != nil {
+		return err
+	}
+	event.Hash = hash
+	line, err := json.Marshal(event)
+	if err != nil {
+		return errors.New("failed to convert event to json")
+	}
+	file, err := os.OpenFile(log.path(logFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.New("failed to open event log")
+	}
+	defer file.Close()
+	_, err = file.Write(append(line, '\n'))
+	if err != nil {
+		return errors.New("failed to write event")
+	}
+	err = file.Sync()
+	if err != nil {
+		return errors.New("failed to sync event log")
+	}
+	log.seq = event.Seq
+	log.hash = event.Hash
+	log.offset += int64(len(line)) + 1
+	// The event stands once it is in the log. A head that was not updated
+	// lags by one event, which the next Open accepts and catches up.
+	log.writeHead()
+	return nil
+}
+
+// Snapshot saves the state after the last appended event.
+func (log *Log) Snapshot(state State) error {
+	if state.Seq != log.seq || state.Hash != log.hash {
+		return errors.New("snapshot does not match the event log")
+	}
+	return saveSnapshot(log.path(snapshotFile), snapshot{Offset: log.offset, State: state})
+}
+
+func (log *Log) open(offset int64, state State) (State, error) {
+	events, end, err := log.read(offset, state.Seq, state.Hash)
+	if err != nil {
+		return State{}, err
+	}
+	for _, event := range events {
+		state.Apply(event)
+	}
+	behind, err := log.checkHead(events, state.Seq, state.Hash)
+	if err != nil {
+		return State{}, err
+	}
+	log.seq = state.Seq
+	log.hash = state.Hash
+	log.offset = end
+	if behind {
+		log.writeHead()
+	}
+	return state, nil
+}
+
+// read returns the events starting at offset, which have to continue the
+// chain after the event numbered seq with the given hash.
+func (log *Log) read(offset, seq int64, hash string) ([]Event, int64, error) {
+	file, err := os.Open(log.path(logFile))
+	if errors.Is(err, os.ErrNotExist) && offset == 0 {
+		return []Event{}, 0, nil
+	}
+	if err != nil {
+		return nil, 0, errors.New("failed to open event log")
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, 0, errors.New("failed to read event log")
+	}
+	if info.Size() < offset {
+		return nil, 0, ErrTruncated
+	}
+	_, err = file.Seek(offset, io.SeekStart)
+	if err != nil {
+		return nil, 0, errors.New("failed to read event log")
+	}
+
+	events := []Event{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		offset += int64(len(scanner.Bytes())) + 1
+		var event Event
+		err = json.Unmarshal(scanner.Bytes(), &event)
+		if err != nil {
+			return nil, 0, fmt.Errorf("event %d: %w", seq+1, ErrTampered)
+		}
+		digest, err := event.digest()
+		if err != nil || event.Seq != seq+1 || event.Prev != hash || event.Hash != digest {
+			return nil, 0, fmt.Errorf("event %d: %w", seq+1, ErrTampered)
+		}
+		seq = event.Seq
+		hash = event.Hash
+		events = append(events, event)
+	}
+	if scanner.Err() != nil {
+		return nil, 0, errors.New("failed to read event log")
+	}
+	return events, offset, nil
+}
+
+// checkHead compares the last event with the head file. The head may lag by
+// one event after a crash right after an append; it reports that so the
+// head can be brought up to date.
+func (log *Log) checkHead(events []Event, seq int64, hash string) (bool, error) {
+	data, err := os.ReadFile(log.path(headFile))
+	if errors.Is(err, os.ErrNotExist) && seq == 0 {
+		return false, nil
+	}
+	var last head
+	if err == nil {
+		err = json.Unmarshal(data, &last)
+	}
+	if err != nil {
+		return false, fmt.Errorf("head: %w", ErrTampered)
+	}
+	switch {
+	case last.Seq > seq:
+		return false, fmt.Errorf("%w: %d of %d events left", ErrTruncated, seq, last.Seq)
+	case last.Seq == seq && last.Hash == hash:
+		return false, nil
+	case last.Seq == seq-1 && len(events) > 0 && events[len(events)-1].Prev == last.Hash:
+		return true, nil
+	}
+	return false, fmt.Errorf("head: %w", ErrTampered)
+}
+
+func (log *Log) writeHead() error {
+	data, err := json.Marshal(head{log.seq, log.hash})
+	if err != nil {
+		return errors.New("failed to convert head to json")
+	}
+	return fileutils.WriteAtomic(log.path(headFile), data)
+}
+
+// repair drops a partially written last line left behind by a crash in the
+// middle of Append.
+func (log *Log) repair() error {
+	data, err := os.ReadFile(log.path(logFile))
+	if errors.Is(err, os.ErrNotExist) || len(data) == 0 {
+		return nil
+	}
+	if err != nil {
+		return errors.New("failed to read event log")
+	}
+	if data[len(data)-1] == '\n' {
+		return nil
+	}
+	lastLine := bytes.LastIndexByte(data, '\n') + 1
+	err = os.Truncate(log.path(logFile), int64(lastLine))
+	if err != nil {
+		return errors.New("failed to repair event log")
+	}
+	return nil
+}
+
+func (log *Log) path(fileName string) string {
+	return filepath.Join(log.dir, fileName)
+}
@@ -0,0 +1,88 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"slices"
+	"time"
+
+	"example.com/bank/fileutils"
+	"example.com/bank/money"
+)
+
+// State is what the events add up to: the open accounts and their balances
+// after the event with number Seq.
+type State struct {
+	Seq      int64                  `json:"seq"`
+	Hash     string                 `json:"hash"`
+	Time     time.Time              `json:"time"`
+	Accounts []Account              `json:"accounts"`
+	Balances map[string]money.Money `json:"balances"`
+}
+
+func NewState() State {
+	return State{Accounts: []Account{}, Balances: map[string]money.Money{}}
+}
+
+func (state *State) Apply(event Event) {
+	state.Seq = event.Seq
+	state.Hash = event.Hash
+	state.Time = event.Time
+	if event.Account != nil && !state.Opened(event.Account.Name) {
+		state.Accounts = append(state.Accounts, *event.Account)
+		state.Balances[event.Account.Name] = 0
+	}
+	if event.Transaction != nil {
+		for _, posting := range event.Transaction.Postings {
+			state.Balances[posting.Account] = posting.Balance
+		}
+	}
+}
+
+func (state *State) Opened(name string) bool {
+	return slices.ContainsFunc(state.Accounts, func(account Account) bool {
+		return account.Name == name
+	})
+}
+
+// Replay rebuilds the state as it was at the given time. A zero time replays
+// all events.
+func Replay(events []Event, until time.Time) State {
+	state := NewState()
+	for _, event := range events {
+		if !until.IsZero() && event.Time.After(until) {
+			break
+		}
+		state.Apply(event)
+	}
+	return state
+}
+
+// snapshot is a saved state together with the position in the log file
+// right after its last event, so startup only reads the events after it.
+type snapshot struct {
+	Offset int64 `json:"offset"`
+	State  State `json:"state"`
+}
+
+func loadSnapshot(fileName string) (snapshot, bool) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return snapshot{}, false
+	}
+	var saved snapshot
+	err = json.Unmarshal(data, &saved)
+	if err != nil || saved.State.Balances == nil {
+		return snapshot{}, false
+	}
+	return saved, true
+}
+
+func saveSnapshot(fileName string, saved snapshot) error {
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return errors.New("failed to convert snapshot to json")
+	}
+	return fileutils.WriteAtomic(fileName, data)
+}
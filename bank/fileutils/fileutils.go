@@ -3,24 +3,123 @@ package fileutils
 import (
 	"errors"
 	"fmt"
+	"hash/crc32"
 	"os"
-	"strconv"
+	"path/filepath"
+	"strings"
+
+	"example.com/bank/money"
 )
 
-func GetFloatFromFile(fileName string) (float64, error) {
+const header = "bank-balance v1"
+const backupSuffix = ".bak"
+
+// GetMoneyFromFile reads a value written by WriteToFile. Files holding a
+// plain float64 from older versions are read as well. When the file is
+// missing, truncated or fails its checksum, the last good backup is restored
+// and returned instead.
+func GetMoneyFromFile(fileName string) (money.Money, error) {
+	value, err := readValue(fileName)
+	if err == nil {
+		return value, nil
+	}
+	backup, backupErr := readValue(fileName + backupSuffix)
+	if backupErr != nil {
+		return 0, err
+	}
+	err = WriteAtomic(fileName, encode(backup))
+	if err != nil {
+		return 0, err
+	}
+	return backup, nil
+}
+
+// WriteToFile replaces the file atomically: the value is written to a
+// temporary file, synced and renamed over the original. The previous value
+// is kept as a backup for GetMoneyFromFile.
+func WriteToFile(value money.Money, fileName string) error {
+	previous, err := readValue(fileName)
+	if err == nil {
+		err = WriteAtomic(fileName+backupSuffix, encode(previous))
+		if err != nil {
+			return err
+		}
+	}
+	return WriteAtomic(fileName, encode(value))
+}
+
+func encode(value money.Money) []byte {
+	valueText := value.String()
+	return []byte(fmt.Sprintf("%s %08x\n%s", header, crc32.ChecksumIEEE([]byte(valueText)), valueText))
+}
+
+func readValue(fileName string) (money.Money, error) {
 	data, err := os.ReadFile(fileName)
 	if err != nil {
 		return 0, errors.New("file not found")
 	}
 	valueText := string(data)
-	value, err := strconv.ParseFloat(valueText, 64)
+	if strings.HasPrefix(valueText, header) {
+		firstLine, rest, found := strings.Cut(valueText, "\n")
+		if !found {
+			return 0, errors.New("file is truncated")
+		}
+		checksum := strings.TrimSpace(strings.TrimPrefix(firstLine, header))
+		if checksum != fmt.Sprintf("%08x", crc32.ChecksumIEEE([]byte(rest))) {
+			return 0, errors.New("checksum mismatch")
+		}
+		valueText = rest
+	}
+	value, err := money.ParseStored(valueText)
 	if err != nil {
-		return 0, errors.New("faild to parse data to float")
+		return 0, errors.New("faild to parse balance")
 	}
 	return value, nil
 }
 
-func WriteToFile(value float64, fileName string) {
-	valueText := fmt.Sprint(value)
-	os.WriteFile(fileName, []byte(valueText), 0644)
+// WriteAtomic replaces the file with data so that readers see either the old
+// or the new content, never a mix of both.
+func WriteAtomic(fileName string, data []byte) error {
+	return WriteAtomicMode(fileName, data, 0644)
+}
+
+// WriteAtomicMode is WriteAtomic for a file with the given permissions, such
+// as 0600 for one only the owner may read.
+func WriteAtomicMode(fileName string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(fileName)
+	file, err := os.CreateTemp(dir, filepath.Base(fileName)+".tmp*")
+	if err != nil {
+		return errors.New("failed to create temporary file")
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+	closeErr := file.Close()
+	if err != nil || closeErr != nil {
+		return errors.New("failed to write temporary file")
+	}
+	err = os.Chmod(file.Name(), perm)
+	if err != nil {
+		return errors.New("failed to set file permissions")
+	}
+	err = os.Rename(file.Name(), fileName)
+	if err != nil {
+		return errors.New("failed to replace file")
+	}
+	syncDir(dir)
+	return nil
+}
+
+// syncDir makes the rename durable. Not every platform can sync a directory,
+// so failures are ignored.
+func syncDir(dir string) {
+	handle, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	defer handle.Close()
+	handle.Sync()
 }
@@ -1,5 +1,3 @@
 module example.com/bank
 
-go 1.23.1
-
-require github.com/Pallinder/go-randomdata v1.2.0 // indirect
+go 1.24
@@ -0,0 +1,247 @@
+package importer
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"example.com/bank/money"
+)
+
+// Profile maps the columns of one bank's CSV export. Columns are given by
+// header name, or by 1-based position when the file has no header.
+type Profile struct {
+	Delimiter    string `json:"delimiter"`
+	HasHeader    bool   `json:"has_header"`
+	Date         string `json:"date"`
+	Amount       string `json:"amount"`
+	Description  string `json:"description"`
+	Reference    string `json:"reference"`
+	DateFormat   string `json:"date_format"`
+	DecimalComma bool   `json:"decimal_comma"`
+}
+
+type Row struct {
+	Line        int
+	Date        time.Time
+	Amount      money.Money
+	Description string
+	Reference   string
+	Hash        string
+}
+
+const DefaultProfile = "default"
+
+var defaultProfiles = map[string]Profile{
+	DefaultProfile: {
+		Delimiter:   ",",
+		HasHeader:   true,
+		Date:        "date",
+		Amount:      "amount",
+		Description: "description",
+		Reference:   "reference",
+		DateFormat:  "2006-01-02",
+	},
+}
+
+func LoadProfile(fileName, name string) (Profile, error) {
+	profiles := defaultProfiles
+	data, err := os.ReadFile(fileName)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return Profile{}, errors.New("failed to read import profiles file")
+	}
+	if err == nil {
+		profiles = map[string]Profile{}
+		err = json.Unmarshal(data, &profiles)
+		if err != nil {
+			return Profile{}, errors.New("failed to parse import profiles file")
+		}
+	}
+	profile, ok := profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("import profile %q does not exist", name)
+	}
+	if profile.Delimiter == "" {
+		profile.Delimiter = ","
+	}
+	if profile.DateFormat == "" {
+		profile.DateFormat = "2006-01-02"
+	}
+	return profile, nil
+}
+
+// Parse reads all rows of a statement. Each row gets a hash identifying it
+// within account, built from its reference, or from date, amount and
+// description when the bank does not provide a reference.
+func Parse(r io.Reader, profile Profile, account string) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = []rune(profile.Delimiter)[0]
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv: %w", err)
+	}
+
+	var header []string
+	first := 0
+	if profile.HasHeader && len(records) > 0 {
+		header = records[0]
+		first = 1
+	}
+	columns := map[string]int{}
+	for field, spec := range map[string]string{
+		"date":        profile.Date,
+		"amount":      profile.Amount,
+		"description": profile.Description,
+		"reference":   profile.Reference,
+	} {
+		index, err := resolveColumn(header, spec)
+		if err != nil && field != "reference" && field != "description" {
+			return nil, fmt.Errorf("%s column: %w", field, err)
+		}
+		if err != nil {
+			index = -1
+		}
+		columns[field] = index
+	}
+
+	rows := []Row{}
+	occurrences := map[string]int{}
+	for i, record := range records[first:] {
+		line := first + i + 1
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		row, err := parseRow(record, columns, profile)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		row.Line = line
+		key := rowKey(row)
+		occurrences[key]++
+		row.Hash = hash(account, key, occurrences[key])
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func resolveColumn(header []string, spec string) (int, error) {
+	if spec == "" {
+		return -1, errors.New("not configured")
+	}
+	for i, name := range header {
+		if strings.EqualFold(strings.TrimSpace(name), spec) {
+			return i, nil
+		}
+	}
+	position, err := strconv.Atoi(spec)
+	if err != nil || position < 1 {
+		return -1, fmt.Errorf("column %q not found", spec)
+	}
+	return position - 1, nil
+}
+
+func parseRow(record []string, columns map[string]int, profile Profile) (Row, error) {
+	field := func(name string) string {
+		index := columns[name]
+		if index < 0 || index >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[index])
+	}
+
+	date, err := time.ParseInLocation(profile.DateFormat, field("date"), time.Local)
+	if err != nil {
+		return Row{}, fmt.Errorf("invalid date %q", field("date"))
+	}
+	amountText := strings.ReplaceAll(field("amount"), " ", "")
+	if profile.DecimalComma {
+		amountText = strings.ReplaceAll(amountText, ".", "")
+		amountText = strings.ReplaceAll(amountText, ",", ".")
+	} else {
+		amountText = strings.ReplaceAll(amountText, ",", "")
+	}
+	amount, err := money.Parse(amountText)
+	if err != nil {
+		return Row{}, fmt.Errorf("invalid amount %q", field("amount"))
+	}
+	if amount == 0 {
+		return Row{}, errors.New("amount is zero")
+	}
+	return Row{
+		Date:        date,
+		Amount:      amount,
+		Description: field("description"),
+		Reference:   field("reference"),
+	}, nil
+}
+
+func rowKey(row Row) string {
+	if row.Reference != "" {
+		return row.Reference
+	}
+	return fmt.Sprintf("%s|%s|%s", row.Date.Format("2006-01-02"), row.Amount, row.Description)
+}
+
+// hash includes the occurrence of the key within the statement, so two
+// identical rows without a reference (two coffees on one day) both import.
+func hash(account, key string, occurrence int) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%d", account, key, occurrence)))
+	return hex.EncodeToString(sum[:])
+}
+
+// Imported is the set of row hashes already posted to the ledger.
+type Imported struct {
+	fileName string
+	hashes   []string
+}
+
+func LoadImported(fileName string) (*Imported, error) {
+	imported := &Imported{fileName: fileName, hashes: []string{}}
+	data, err := os.ReadFile(fileName)
+	if errors.Is(err, os.ErrNotExist) {
+		return imported, nil
+	}
+	if err != nil {
+		return nil, errors.New("failed to read imported rows file")
+	}
+	err = json.Unmarshal(data, &imported.hashes)
+	if err != nil {
+		return nil, errors.New("failed to parse imported rows file")
+	}
+	return imported, nil
+}
+
+func (imported *Imported) Contains(hash string) bool {
+	return slices.Contains(imported.hashes, hash)
+}
+
+func (imported *Imported) Add(hash string) error {
+	imported.hashes = append(imported.hashes, hash)
+	return imported.save()
+}
+
+func (imported *Imported) Remove(hash string) error {
+	imported.hashes = slices.DeleteFunc(imported.hashes, func(existing string) bool {
+		return existing == hash
+	})
+	return imported.save()
+}
+
+func (imported *Imported) save() error {
+	data, err := json.Marshal(imported.hashes)
+	if err != nil {
+		return errors.New("failed to convert imported rows to json")
+	}
+	return os.WriteFile(imported.fileName, data, 0644)
+}
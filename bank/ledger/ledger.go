@@ -0,0 +1,274 @@
+package ledger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"example.com/bank/money"
+)
+
+const (
+	Deposit    = "deposit"
+	Withdrawal = "withdrawal"
+	Transfer   = "transfer"
+	Interest   = "interest"
+	Fee        = "fee"
+)
+
+// Posting is one side of a transaction. Amount is signed: positive values
+// credit the account, negative values debit it.
+type Posting struct {
+	Account string      `json:"account"`
+	Amount  money.Money `json:"amount"`
+	Balance money.Money `json:"balance"`
+}
+
+// Exchange records the conversion applied to a transfer between accounts
+// held in different currencies. Fee is in the From currency.
+type Exchange struct {
+	From string      `json:"from"`
+	To   string      `json:"to"`
+	Rate float64     `json:"rate"`
+	Fee  money.Money `json:"fee"`
+}
+
+// Details describe what a transaction was for. Category groups spending for
+// budgets and reports; tags are free-form labels.
+type Details struct {
+	Memo     string   `json:"memo,omitempty"`
+	Category string   `json:"category,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// Label is the memo followed by the category and tags, for listings.
+func (details Details) Label() string {
+	parts := []string{}
+	if details.Memo != "" {
+		parts = append(parts, details.Memo)
+	}
+	if details.Category != "" {
+		parts = append(parts, "["+details.Category+"]")
+	}
+	for _, tag := range details.Tags {
+		parts = append(parts, "#"+tag)
+	}
+	return strings.Join(parts, " ")
+}
+
+type Transaction struct {
+	Time time.Time `json:"time"`
+	Kind string    `json:"kind"`
+	Details
+	Postings []Posting `json:"postings"`
+	Exchange *Exchange `json:"exchange,omitempty"`
+}
+
+func NewDeposit(account string, amount, balance money.Money, details Details) Transaction {
+	return newTransaction(Deposit, details, Posting{account, amount, balance})
+}
+
+func NewWithdrawal(account string, amount, balance money.Money, details Details) Transaction {
+	return newTransaction(Withdrawal, details, Posting{account, -amount, balance})
+}
+
+func NewInterest(account string, amount, balance money.Money, memo string) Transaction {
+	return newTransaction(Interest, Details{Memo: memo, Category: Interest}, Posting{account, amount, balance})
+}
+
+func NewFee(account string, amount, balance money.Money, memo string) Transaction {
+	return newTransaction(Fee, Details{Memo: memo, Category: Fee}, Posting{account, -amount, balance})
+}
+
+// NewTransfer builds both sides of a transfer as a single transaction, so
+// the debit and the credit are always written (or lost) together.
+func NewTransfer(from, to string, amount, fromBalance, toBalance money.Money, details Details) Transaction {
+	return newTransaction(Transfer, details,
+		Posting{from, -amount, fromBalance},
+		Posting{to, amount, toBalance})
+}
+
+// NewExchangeTransfer is a transfer between accounts in different
+// currencies: amount is taken from the source account and credit, the
+// converted amount after the fee, is added to the target account.
+func NewExchangeTransfer(from, to string, amount, credit, fromBalance, toBalance money.Money, exchange Exchange, details Details) Transaction {
+	transaction := newTransaction(Transfer, details,
+		Posting{from, -amount, fromBalance},
+		Posting{to, credit, toBalance})
+	transaction.Exchange = &exchange
+	return transaction
+}
+
+func newTransaction(kind string, details Details, postings ...Posting) Transaction {
+	return Transaction{
+		Time:     time.Now(),
+		Kind:     kind,
+		Details:  details,
+		Postings: postings,
+	}
+}
+
+func (transaction Transaction) PostingFor(account string) (Posting, bool) {
+	for _, posting := range transaction.Postings {
+		if posting.Account == account {
+			return posting, true
+		}
+	}
+	return Posting{}, false
+}
+
+// storedMoney reads amounts of history files written before amounts were
+// kept in minor units, which hold float64 values.
+type storedMoney money.Money
+
+func (m *storedMoney) UnmarshalJSON(data []byte) error {
+	value, err := money.ParseStored(strings.Trim(string(data), `"`))
+	if err != nil {
+		return err
+	}
+	*m = storedMoney(value)
+	return nil
+}
+
+func (posting *Posting) UnmarshalJSON(data []byte) error {
+	var entry struct {
+		Account string      `json:"account"`
+		Amount  storedMoney `json:"amount"`
+		Balance storedMoney `json:"balance"`
+	}
+	err := json.Unmarshal(data, &entry)
+	if err != nil {
+		return err
+	}
+	*posting = Posting{entry.Account, money.Money(entry.Amount), money.Money(entry.Balance)}
+	return nil
+}
+
+// UnmarshalJSON also accepts single-account entries written before
+// transactions carried postings and treats them as entries of the main account.
+func (transaction *Transaction) UnmarshalJSON(data []byte) error {
+	type plain Transaction
+	var entry struct {
+		plain
+		Amount  storedMoney `json:"amount"`
+		Balance storedMoney `json:"balance"`
+	}
+	err := json.Unmarshal(data, &entry)
+	if err != nil {
+		return err
+	}
+	*transaction = Transaction(entry.plain)
+	if len(transaction.Postings) == 0 && entry.Amount != 0 {
+		amount := money.Money(entry.Amount)
+		if transaction.Kind == Withdrawal {
+			amount = -amount
+		}
+		transaction.Postings = []Posting{{"main", amount, money.Money(entry.Balance)}}
+	}
+	return nil
+}
+
+// Repair drops a partially written last line left behind by a crash in the
+// middle of a write.
+func Repair(fileName string) error {
+	data, err := os.ReadFile(fileName)
+	if errors.Is(err, os.ErrNotExist) || len(data) == 0 {
+		return nil
+	}
+	if err != nil {
+		return errors.New("failed to read history file")
+	}
+	if data[len(data)-1] == '\n' {
+		return nil
+	}
+	lastLine := bytes.LastIndexByte(data, '\n') + 1
+	err = os.Truncate(fileName, int64(lastLine))
+	if err != nil {
+		return errors.New("failed to repair history file")
+	}
+	return nil
+}
+
+func Load(fileName string) ([]Transaction, error) {
+	file, err := os.Open(fileName)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Transaction{}, nil
+	}
+	if err != nil {
+		return nil, errors.New("failed to open history file")
+	}
+	defer file.Close()
+
+	transactions := []Transaction{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var transaction Transaction
+		err = json.Unmarshal(scanner.Bytes(), &transaction)
+		if err != nil {
+			return nil, errors.New("failed to parse history file")
+		}
+		transactions = append(transactions, transaction)
+	}
+	if scanner.Err() != nil {
+		return nil, errors.New("failed to read history file")
+	}
+	return transactions, nil
+}
+
+// Filter returns the transactions of the account made between from and to,
+// both inclusive. A zero time leaves that side of the range open.
+func Filter(transactions []Transaction, account string, from, to time.Time) []Transaction {
+	result := []Transaction{}
+	for _, transaction := range transactions {
+		if _, ok := transaction.PostingFor(account); !ok {
+			continue
+		}
+		if !from.IsZero() && transaction.Time.Before(from) {
+			continue
+		}
+		if !to.IsZero() && transaction.Time.After(to) {
+			continue
+		}
+		result = append(result, transaction)
+	}
+	return result
+}
+
+// Balances returns the balance of every account after its latest posting.
+func Balances(transactions []Transaction) map[string]money.Money {
+	balances := make(map[string]money.Money)
+	for _, transaction := range transactions {
+		for _, posting := range transaction.Postings {
+			balances[posting.Account] = posting.Balance
+		}
+	}
+	return balances
+}
+
+// BalanceAt returns the balance of the account at time t. ok is false when
+// the ledger holds no postings for the account.
+func BalanceAt(transactions []Transaction, account string, t time.Time) (money.Money, bool) {
+	var balance money.Money
+	found := false
+	for _, transaction := range transactions {
+		posting, ok := transaction.PostingFor(account)
+		if !ok {
+			continue
+		}
+		if transaction.Time.After(t) {
+			if !found {
+				return posting.Balance - posting.Amount, true
+			}
+			break
+		}
+		balance, found = posting.Balance, true
+	}
+	return balance, found
+}
@@ -0,0 +1,76 @@
+package ledger
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"example.com/bank/money"
+)
+
+const dateTimeLayout = "2006-01-02 15:04"
+
+func WriteCSV(w io.Writer, account string, transactions []Transaction) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"time", "kind", "amount", "balance", "memo", "category", "tags"})
+	for _, transaction := range transactions {
+		posting, ok := transaction.PostingFor(account)
+		if !ok {
+			continue
+		}
+		writer.Write([]string{
+			transaction.Time.Format(time.RFC3339),
+			transaction.Kind,
+			posting.Amount.String(),
+			posting.Balance.String(),
+			transaction.Memo,
+			transaction.Category,
+			strings.Join(transaction.Tags, ","),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func WriteStatement(w io.Writer, bankName, account string, transactions []Transaction) error {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("%s - Account statement: %s\n", bankName, account))
+	builder.WriteString(fmt.Sprintf("Generated: %s\n\n", time.Now().Format(dateTimeLayout)))
+	builder.WriteString(fmt.Sprintf("%-16s  %-10s  %12s  %12s  %s\n", "Date", "Type", "Amount", "Balance", "Memo"))
+	builder.WriteString(strings.Repeat("-", 72) + "\n")
+
+	var credits, debits, closing money.Money
+	count := 0
+	for _, transaction := range transactions {
+		posting, ok := transaction.PostingFor(account)
+		if !ok {
+			continue
+		}
+		if posting.Amount < 0 {
+			debits -= posting.Amount
+		} else {
+			credits += posting.Amount
+		}
+		closing = posting.Balance
+		count++
+		builder.WriteString(fmt.Sprintf("%-16s  %-10s  %12s  %12s  %s\n",
+			transaction.Time.Format(dateTimeLayout),
+			transaction.Kind,
+			posting.Amount,
+			posting.Balance,
+			transaction.Label()))
+	}
+
+	builder.WriteString(strings.Repeat("-", 72) + "\n")
+	builder.WriteString(fmt.Sprintf("Transactions: %d\n", count))
+	builder.WriteString(fmt.Sprintf("Total credits: %s\n", credits))
+	builder.WriteString(fmt.Sprintf("Total debits: %s\n", debits))
+	if count > 0 {
+		builder.WriteString(fmt.Sprintf("Closing balance: %s\n", closing))
+	}
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
@@ -1,68 +1,176 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"strings"
 
-	"example.com/bank/fileutils"
-	"github.com/Pallinder/go-randomdata"
+	"example.com/bank/accounts"
+	"example.com/bank/auth"
+	"example.com/bank/messages"
+	"example.com/bank/money"
 )
 
-const accountBalanceFile = "balance.txt"
-
 func main() {
+	args, language := languageFlag(os.Args[1:])
+	bank, err := loadConfig(language)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "bank:", messages.Error(err))
+		os.Exit(exitInvalidInput)
+	}
+	if len(args) > 0 {
+		os.Exit(runCommand(bank, args))
+	}
+
 	var isExit = false
-	var balance, err = fileutils.GetFloatFromFile(accountBalanceFile)
+	var account = accounts.MainAccount
+
+	service, err := accounts.NewService(".")
+	var credentials auth.Store
+	if err == nil {
+		credentials, err = auth.Load(credentialsFile)
+	}
 
 	if err != nil {
-		fmt.Println("ERROR:")
-		fmt.Println(err)
-		fmt.Println("-----------------------")
+		fmt.Println(messages.T("ERROR:"))
+		fmt.Println(messages.Error(err))
+		fmt.Println(messages.T("-----------------------"))
 		panic("Can't continue")
 	}
 
-	fmt.Println("Welcome to Go Bank!")
-	fmt.Println("Reach us 27/7", randomdata.PhoneNumber())
+	exitOnInterrupt(service)
 
-	for !isExit {
-		presentOptions()
+	welcome(bank)
+	runScheduler(service)
 
-		var choice int
-		fmt.Scan(&choice)
+	if name := readLine(messages.T("Account (empty for main): ")); name != "" {
+		account = name
+	}
+	if !login(service, credentials, account, "") {
+		fmt.Println(messages.T("Goodbye!"))
+		return
+	}
 
-		fmt.Println("Your choice: ", choice)
+	for !isExit {
+		presentOptions(account)
+
+		choice := readChoice()
 		if choice == 1 {
-			fmt.Println("Your balance is ", balance)
+			showBalance(service, account)
 		} else if choice == 2 {
-			fmt.Print("Your deposit: ")
-			var depositAmount float64
-			fmt.Scan(&depositAmount)
-			if depositAmount <= 0 {
-				fmt.Print("Deposit smaller then 0. Invalid input")
-				return
-			}
-			balance += depositAmount
-			fmt.Println("Your current balance: ", balance)
-			fileutils.WriteToFile(balance, accountBalanceFile)
+			depositAmount := readAmount(messages.T("Your deposit: "))
+			_, err = service.Deposit(account, depositAmount, readDetails())
+			reportTransaction(service, account, err)
 		} else if choice == 3 {
-			fmt.Print("How much do you want to withdraw: ")
-			var withdraw float64
-			fmt.Scan(&withdraw)
-			if withdraw <= 0 {
-				fmt.Print("withdraw smaller then 0. Invalid input")
+			withdraw := readAmount(messages.T("How much do you want to withdraw: "))
+			if withdraw >= largeWithdrawal && !confirm(messages.F("Withdraw %s?", withdraw)) {
+				fmt.Println(messages.T("Withdrawal cancelled"))
 				continue
 			}
-			if withdraw > balance {
-				fmt.Print("Invalid input. Withdraw greater then balance")
-				continue
+			details := readDetails()
+			_, err = service.Withdraw(account, withdraw, details)
+			reportTransaction(service, account, err)
+			if err == nil {
+				checkBudget(os.Stdout, service, account, details.Category)
 			}
-			balance -= withdraw
-			fmt.Println("Your current balance: ", balance)
-			fileutils.WriteToFile(balance, accountBalanceFile)
 		} else if choice == 4 {
-			fmt.Println("Goodbye!")
+			to := readLine(messages.T("Transfer to account: "))
+			amount := readAmount(messages.T("Amount: "))
+			if amount >= largeWithdrawal && !confirm(messages.F("Transfer %s to %s?", amount, to)) {
+				fmt.Println(messages.T("Transfer cancelled"))
+				continue
+			}
+			details := readDetails()
+			_, err = service.Transfer(account, to, amount, details)
+			reportTransaction(service, account, err)
+			if err == nil {
+				checkBudget(os.Stdout, service, account, details.Category)
+			}
+		} else if choice == 5 {
+			showHistory(service, account)
+		} else if choice == 6 {
+			exportStatement(bank, service, account)
+		} else if choice == 7 {
+			name := readLine(messages.T("New account name: "))
+			product := readProduct(service)
+			currencyCode := readLine(messages.F("Currency %v: ", service.Currencies()))
+			err = service.Open(name, product, currencyCode)
+			if err != nil {
+				fmt.Println(messages.T("Could not open account:"), messages.Error(err))
+				continue
+			}
+			fmt.Println(messages.T("Account opened"))
+			setPIN(credentials, name)
+		} else if choice == 8 {
+			fmt.Println(messages.T("Accounts:"), service.Accounts())
+			name := readLine(messages.T("Switch to account: "))
+			if !login(service, credentials, name, account) {
+				continue
+			}
+			account = name
+		} else if choice == 9 {
+			changePIN(credentials, account)
+		} else if choice == 10 {
+			manageStandingOrders(account)
+		} else if choice == 11 {
+			showNetWorth(service)
+		} else if choice == 12 {
+			manageBudgets(service, account)
+		} else if choice == 13 {
+			fmt.Println(messages.T("Goodbye!"))
 			isExit = true
-		} else {
-			fmt.Println("Invalid input")
 		}
 	}
 }
+
+// reportTransaction prints the outcome of a service call followed by the new
+// balance, which already includes any fee charged for the transaction.
+func reportTransaction(service *accounts.Service, account string, err error) {
+	if err != nil && !errors.Is(err, accounts.ErrBalanceNotSaved) {
+		fmt.Println(messages.T("Transaction failed:"), messages.Error(err))
+		return
+	}
+	if err != nil {
+		fmt.Println(messages.T("Warning:"), messages.Error(err))
+	}
+	balance, _ := service.Balance(account)
+	details, _ := service.Details(account)
+	fmt.Println(messages.T("Your current balance:"), balance, details.Currency)
+}
+
+func showBalance(service *accounts.Service, account string) {
+	balance, _ := service.Balance(account)
+	details, _ := service.Details(account)
+	fmt.Println(messages.T("Your balance is"), balance, details.Currency)
+	fmt.Println(messages.T("Account type:"), details.Product.Name)
+	if details.Product.OverdraftLimit > 0 {
+		fmt.Print(messages.F("Overdraft limit: %s (fee %s)\n", details.Product.OverdraftLimit, details.Product.OverdraftFee))
+	}
+	if details.Product.InterestRate > 0 {
+		fmt.Print(messages.F("Interest rate: %.2f%% per year, accrued this month: %s\n",
+			details.Product.InterestRate, money.Money(details.AccruedInterest)))
+	}
+}
+
+func readProduct(service *accounts.Service) string {
+	for _, product := range service.Products() {
+		fmt.Print(messages.F("- %s (interest %.2f%%, overdraft %s)\n", product.Name, product.InterestRate, product.OverdraftLimit))
+	}
+	return readLine(messages.T("Account type: "))
+}
+
+func showNetWorth(service *accounts.Service) {
+	base := strings.ToUpper(readLine(messages.F("Base currency %v: ", service.Currencies())))
+	for _, name := range service.Accounts() {
+		balance, _ := service.Balance(name)
+		details, _ := service.Details(name)
+		fmt.Printf("%-16s %12s %s\n", name, balance, details.Currency)
+	}
+	total, err := service.NetWorth(base)
+	if err != nil {
+		fmt.Println(messages.T("Could not compute net worth:"), messages.Error(err))
+		return
+	}
+	fmt.Printf("%-16s %12s %s\n", messages.T("Net worth"), total, base)
+}
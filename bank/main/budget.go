@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"example.com/bank/accounts"
+	"example.com/bank/budget"
+	"example.com/bank/ledger"
+	"example.com/bank/messages"
+	"example.com/bank/money"
+)
+
+const budgetsFile = "budgets.json"
+
+func readDetails() ledger.Details {
+	memo := readLine(messages.T("Memo (optional): "))
+	category := readLine(messages.T("Category (optional): "))
+	tags := readLine(messages.T("Tags (comma separated, optional): "))
+	return ledger.Details{Memo: memo, Category: category, Tags: parseTags(tags)}
+}
+
+func parseTags(text string) []string {
+	var tags []string
+	for _, tag := range strings.Split(text, ",") {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// checkBudget writes a warning to out when the spending of a category this
+// month went over its budget.
+func checkBudget(out io.Writer, service *accounts.Service, account, category string) {
+	if category == "" {
+		return
+	}
+	budgets, err := budget.Load(budgetsFile)
+	if err != nil {
+		return
+	}
+	limit, ok := budgets.Limit(account, category)
+	if !ok {
+		return
+	}
+	month := budget.MonthOf(time.Now())
+	transactions, err := service.History(account, month, time.Time{})
+	if err != nil {
+		return
+	}
+	spent := budget.Spending(transactions, account, month)[budget.Category(category)]
+	if spent > limit {
+		fmt.Fprint(out, messages.F("Budget alert: %s spent %s of %s this month\n", budget.Category(category), spent, limit))
+	}
+}
+
+func writeReport(service *accounts.Service, account string, months int) error {
+	budgets, err := budget.Load(budgetsFile)
+	if err != nil {
+		return err
+	}
+	current := budget.MonthOf(time.Now())
+	first := current.AddDate(0, 1-months, 0)
+	transactions, err := service.History(account, first, time.Time{})
+	if err != nil {
+		return err
+	}
+	var list []time.Time
+	for month := first; !month.After(current); month = month.AddDate(0, 1, 0) {
+		list = append(list, month)
+	}
+	return budget.WriteReport(os.Stdout, transactions, account, list, budgets)
+}
+
+func manageBudgets(service *accounts.Service, account string) {
+	action := strings.ToLower(readLine(messages.T("(r)eport or (s)et budget: ")))
+	if action == "r" {
+		months := readNumber(messages.T("Number of months: "), 1, 24)
+		err := writeReport(service, account, months)
+		if err != nil {
+			fmt.Println(messages.T("Could not write report:"), messages.Error(err))
+		}
+	} else if action == "s" {
+		budgets, err := budget.Load(budgetsFile)
+		if err != nil {
+			fmt.Println(messages.T("Could not load budgets:"), messages.Error(err))
+			return
+		}
+		category := readLine(messages.T("Category: "))
+		var limit money.Money
+		if text := readLine(messages.T("Monthly limit (empty removes the budget): ")); text != "" {
+			limit, err = money.Parse(text)
+			if err != nil {
+				fmt.Println(messages.T("Invalid input."), messages.Error(err))
+				return
+			}
+		}
+		budgets.Set(account, category, limit)
+		err = budgets.Save(budgetsFile)
+		if err != nil {
+			fmt.Println(messages.T("Could not save budgets:"), messages.Error(err))
+			return
+		}
+		if limit <= 0 {
+			fmt.Println(messages.T("Budget removed"))
+			return
+		}
+		fmt.Println(messages.T("Budget saved"))
+	}
+}
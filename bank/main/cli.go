@@ -0,0 +1,316 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"example.com/bank/accounts"
+	"example.com/bank/api"
+	"example.com/bank/auth"
+	"example.com/bank/config"
+	"example.com/bank/currency"
+	"example.com/bank/importer"
+	"example.com/bank/ledger"
+	"example.com/bank/messages"
+	"example.com/bank/money"
+)
+
+// Exit codes of the non-interactive commands.
+const (
+	exitOK                = 0
+	exitError             = 1
+	exitInvalidInput      = 2
+	exitInsufficientFunds = 3
+	exitAuthFailed        = 4
+)
+
+const usage = `Usage: bank [command] [flags]
+
+Without a command the interactive menu is started.
+
+Commands:
+  balance                     print the account balance
+  deposit <amount>            deposit money
+  withdraw <amount>           withdraw money
+  transfer <to> <amount>      transfer money to another account
+  history                     list transactions (--from, --to, --json)
+  networth                    print all accounts converted to --base currency
+  report                      spending per category and budgets (--months)
+  import <file.csv>           import a bank statement (--profile, --dry-run)
+  serve                       serve the REST API (--addr, --daemon)
+  daemon                      post standing orders and interest as they fall due
+  replay                      rebuild the balances from the event log (--at)
+  verify                      check the event log and snapshot for tampering
+
+Deposit, withdraw and transfer take --memo, --category and --tags.
+Account commands take --account (default main) and --pin; the PIN can also
+be set in the BANK_PIN environment variable.
+
+--lang (or BANK_LANG) selects the language of the messages: en or de.
+`
+
+type commandError struct {
+	code int
+	err  error
+}
+
+func (e commandError) Error() string {
+	return e.err.Error()
+}
+
+func invalidInput(err error) error {
+	return commandError{exitInvalidInput, err}
+}
+
+func runCommand(bank config.Config, args []string) int {
+	err := dispatch(bank, args[0], args[1:])
+	if err == nil {
+		return exitOK
+	}
+	fmt.Fprintln(os.Stderr, "bank:", messages.Error(err))
+
+	var command commandError
+	switch {
+	case errors.As(err, &command):
+		return command.code
+	case errors.Is(err, accounts.ErrInsufficientFunds):
+		return exitInsufficientFunds
+	case errors.Is(err, auth.ErrWrongPIN), errors.Is(err, auth.ErrLocked), errors.Is(err, auth.ErrNoPIN):
+		return exitAuthFailed
+	case errors.Is(err, accounts.ErrInvalidAmount),
+		errors.Is(err, accounts.ErrSameAccount),
+		errors.Is(err, currency.ErrUnknownCurrency),
+		errors.Is(err, accounts.ErrUnknownAccount):
+		return exitInvalidInput
+	default:
+		return exitError
+	}
+}
+
+func dispatch(bank config.Config, command string, args []string) error {
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	account := flags.String("account", accounts.MainAccount, "account to use")
+	pin := flags.String("pin", os.Getenv("BANK_PIN"), "account PIN")
+	memo := flags.String("memo", "", "transaction memo")
+	category := flags.String("category", "", "transaction category")
+	tags := flags.String("tags", "", "comma separated transaction tags")
+	months := flags.Int("months", 3, "number of months in the report")
+	from := flags.String("from", "", "first day (YYYY-MM-DD)")
+	to := flags.String("to", "", "last day (YYYY-MM-DD)")
+	asJSON := flags.Bool("json", false, "print JSON")
+	addr := flags.String("addr", "localhost:8080", "address to listen on")
+	daemon := flags.Bool("daemon", false, "also run the scheduler")
+	at := flags.String("at", "", "point in time to replay to")
+	base := flags.String("base", currency.Default, "currency for the net worth")
+	profile := flags.String("profile", importer.DefaultProfile, "import profile")
+	dryRun := flags.Bool("dry-run", false, "preview an import without posting")
+
+	if command == "help" || command == "-h" || command == "--help" {
+		fmt.Print(messages.T(usage))
+		return nil
+	}
+	switch command {
+	case "balance", "deposit", "withdraw", "transfer", "history", "networth", "report", "import", "serve", "daemon",
+		"replay", "verify":
+	default:
+		return invalidInput(fmt.Errorf(messages.T("unknown command %q, see 'bank help'"), command))
+	}
+	positional, err := parseInterspersed(flags, args)
+	if err != nil {
+		return invalidInput(err)
+	}
+
+	// The event log commands work on the files directly, so they also run
+	// when the service refuses to start on a damaged log.
+	switch command {
+	case "verify":
+		err = expectArgs(positional, 0)
+		if err != nil {
+			return err
+		}
+		return verifyLog(*asJSON)
+	case "replay":
+		err = expectArgs(positional, 0)
+		if err != nil {
+			return err
+		}
+		until, err := parseMoment(*at)
+		if err != nil {
+			return invalidInput(err)
+		}
+		return replayLog(until, *asJSON)
+	}
+
+	service, err := accounts.NewService(".")
+	if err != nil {
+		return err
+	}
+	credentials, err := auth.Load(credentialsFile)
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "serve":
+		ctx, stop := interrupted()
+		defer stop()
+		if *daemon {
+			go runDaemon(ctx, service)
+		}
+		fmt.Println(messages.F("%s API listening on %s", bank.Name, *addr))
+		return serve(ctx, *addr, api.New(service, credentials, credentialsFile).Handler())
+	case "daemon":
+		ctx, stop := interrupted()
+		defer stop()
+		runDaemon(ctx, service)
+		return nil
+	}
+
+	if !service.Exists(*account) {
+		return invalidInput(accounts.ErrUnknownAccount)
+	}
+	err = credentials.Verify(*account, *pin, time.Now())
+	saveErr := credentials.Save(credentialsFile)
+	if err != nil {
+		return err
+	}
+	if saveErr != nil {
+		return saveErr
+	}
+	runScheduler(service)
+
+	switch command {
+	case "balance":
+		err = expectArgs(positional, 0)
+		if err != nil {
+			return err
+		}
+		balance, err := service.Balance(*account)
+		if err != nil {
+			return err
+		}
+		return writeOutput(*asJSON, map[string]any{"account": *account, "balance": balance}, balance)
+	case "networth":
+		err = expectArgs(positional, 0)
+		if err != nil {
+			return err
+		}
+		total, err := service.NetWorth(*base)
+		if err != nil {
+			return err
+		}
+		return writeOutput(*asJSON, map[string]any{"currency": strings.ToUpper(*base), "net_worth": total}, total)
+	case "report":
+		err = expectArgs(positional, 0)
+		if err != nil {
+			return err
+		}
+		if *months < 1 {
+			return invalidInput(errors.New("months must be at least 1"))
+		}
+		return writeReport(service, *account, *months)
+	case "import":
+		err = expectArgs(positional, 1)
+		if err != nil {
+			return err
+		}
+		return importStatement(service, *account, positional[0], *profile, *dryRun)
+	case "deposit", "withdraw":
+		err = expectArgs(positional, 1)
+		if err != nil {
+			return err
+		}
+		amount, err := money.Parse(positional[0])
+		if err != nil {
+			return invalidInput(err)
+		}
+		operation := service.Deposit
+		if command == "withdraw" {
+			operation = service.Withdraw
+		}
+		details := ledger.Details{Memo: *memo, Category: *category, Tags: parseTags(*tags)}
+		transaction, err := operation(*account, amount, details)
+		if err != nil && !errors.Is(err, accounts.ErrBalanceNotSaved) {
+			return err
+		}
+		checkBudget(os.Stderr, service, *account, details.Category)
+		balance, _ := service.Balance(*account)
+		return writeOutput(*asJSON, transaction, balance)
+	case "transfer":
+		err = expectArgs(positional, 2)
+		if err != nil {
+			return err
+		}
+		amount, err := money.Parse(positional[1])
+		if err != nil {
+			return invalidInput(err)
+		}
+		details := ledger.Details{Memo: *memo, Category: *category, Tags: parseTags(*tags)}
+		transaction, err := service.Transfer(*account, positional[0], amount, details)
+		if err != nil && !errors.Is(err, accounts.ErrBalanceNotSaved) {
+			return err
+		}
+		checkBudget(os.Stderr, service, *account, details.Category)
+		balance, _ := service.Balance(*account)
+		return writeOutput(*asJSON, transaction, balance)
+	default:
+		err = expectArgs(positional, 0)
+		if err != nil {
+			return err
+		}
+		fromDate, toDate, err := dateRange(*from, *to)
+		if err != nil {
+			return invalidInput(err)
+		}
+		transactions, err := service.History(*account, fromDate, toDate)
+		if err != nil {
+			return err
+		}
+		if *asJSON {
+			return writeOutput(true, transactions, nil)
+		}
+		printTransactions(*account, transactions)
+		return nil
+	}
+}
+
+// parseInterspersed allows flags after positional arguments, as in
+// "bank deposit 50 --memo salary".
+func parseInterspersed(flags *flag.FlagSet, args []string) ([]string, error) {
+	positional := []string{}
+	for {
+		err := flags.Parse(args)
+		if err != nil {
+			return nil, err
+		}
+		if flags.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
+
+func expectArgs(args []string, count int) error {
+	if len(args) != count {
+		return invalidInput(fmt.Errorf(messages.T("expected %d argument(s), got %d"), count, len(args)))
+	}
+	return nil
+}
+
+func writeOutput(asJSON bool, data any, text any) error {
+	if !asJSON {
+		fmt.Println(text)
+		return nil
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
@@ -1,11 +1,89 @@
 package main
 
-import "fmt"
-
-func presentOptions() {
-	fmt.Println("What do you want to do?")
-	fmt.Println("1 Check balance")
-	fmt.Println("2 Deposit Money")
-	fmt.Println("3 Withdraw Money")
-	fmt.Println("4 Exit")
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"example.com/bank/config"
+	"example.com/bank/messages"
+)
+
+const menuOptions = 13
+
+const configFile = "bank.json"
+
+// loadConfig reads the bank config and selects the language: the --lang
+// flag comes first, then BANK_LANG, then the config file.
+func loadConfig(language string) (config.Config, error) {
+	bank, err := config.Load(configFile)
+	if err != nil {
+		return config.Config{}, err
+	}
+	if language == "" {
+		language = os.Getenv("BANK_LANG")
+	}
+	if language == "" {
+		language = bank.Language
+	}
+	if language == "" {
+		language = messages.Default
+	}
+	err = messages.SetLanguage(language)
+	if err != nil {
+		return config.Config{}, fmt.Errorf("%w: %s (%s)", err, language, strings.Join(messages.Languages(), ", "))
+	}
+	return bank, nil
+}
+
+// languageFlag takes --lang out of the arguments, so it can be given to the
+// menu as well as to every command.
+func languageFlag(args []string) ([]string, string) {
+	rest := []string{}
+	language := ""
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(args[i], "-"), "=")
+		if !strings.HasPrefix(args[i], "-") || name != "lang" {
+			rest = append(rest, args[i])
+			continue
+		}
+		if !hasValue && i+1 < len(args) {
+			i++
+			value = args[i]
+		}
+		language = value
+	}
+	return rest, language
+}
+
+func welcome(bank config.Config) {
+	fmt.Println(messages.F("Welcome to %s!", bank.Name))
+	if bank.Phone != "" && bank.Hours != "" {
+		fmt.Println(messages.F("Reach us %s at %s", bank.Hours, bank.Phone))
+	} else if bank.Phone != "" {
+		fmt.Println(messages.F("Reach us at %s", bank.Phone))
+	}
+	if bank.Email != "" {
+		fmt.Println(messages.F("Email: %s", bank.Email))
+	}
+	if bank.Address != "" {
+		fmt.Println(messages.F("Visit us at %s", bank.Address))
+	}
+}
+
+func presentOptions(account string) {
+	fmt.Println(messages.F("What do you want to do? (account: %s)", account))
+	fmt.Println("1", messages.T("Check balance"))
+	fmt.Println("2", messages.T("Deposit Money"))
+	fmt.Println("3", messages.T("Withdraw Money"))
+	fmt.Println("4", messages.T("Transfer Money"))
+	fmt.Println("5", messages.T("Transaction history"))
+	fmt.Println("6", messages.T("Export statement"))
+	fmt.Println("7", messages.T("Open account"))
+	fmt.Println("8", messages.T("Switch account"))
+	fmt.Println("9", messages.T("Change PIN"))
+	fmt.Println("10", messages.T("Standing orders"))
+	fmt.Println("11", messages.T("Net worth"))
+	fmt.Println("12", messages.T("Budgets and spending report"))
+	fmt.Println("13", messages.T("Exit"))
 }
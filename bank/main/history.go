@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"example.com/bank/accounts"
+	"example.com/bank/config"
+	"example.com/bank/ledger"
+	"example.com/bank/messages"
+)
+
+const dateLayout = "2006-01-02"
+
+func showHistory(service *accounts.Service, account string) {
+	transactions, ok := loadTransactions(service, account)
+	if !ok {
+		return
+	}
+	printTransactions(account, transactions)
+}
+
+func printTransactions(account string, transactions []ledger.Transaction) {
+	if len(transactions) == 0 {
+		fmt.Println(messages.T("No transactions found"))
+		return
+	}
+	for _, transaction := range transactions {
+		posting, _ := transaction.PostingFor(account)
+		fmt.Print(messages.F("%s  %-10s %10s  balance %10s  %s\n",
+			transaction.Time.Format("2006-01-02 15:04"),
+			messages.T(transaction.Kind),
+			posting.Amount,
+			posting.Balance,
+			transaction.Label()))
+	}
+}
+
+func exportStatement(bank config.Config, service *accounts.Service, account string) {
+	transactions, ok := loadTransactions(service, account)
+	if !ok {
+		return
+	}
+	format := strings.ToLower(readLine(messages.T("Format (csv/text): ")))
+	if format != "csv" && format != "text" {
+		fmt.Println(messages.T("Invalid input. Unknown format"))
+		return
+	}
+
+	fileName := "statement_" + account + ".txt"
+	if format == "csv" {
+		fileName = "statement_" + account + ".csv"
+	}
+	file, err := os.Create(fileName)
+	if err != nil {
+		fmt.Println(messages.T("Could not create statement file"))
+		return
+	}
+	defer file.Close()
+
+	if format == "csv" {
+		err = ledger.WriteCSV(file, account, transactions)
+	} else {
+		err = ledger.WriteStatement(file, bank.Name, account, transactions)
+	}
+	if err != nil {
+		fmt.Println(messages.T("Could not write statement:"), messages.Error(err))
+		return
+	}
+	fmt.Println(messages.T("Statement saved to"), fileName)
+}
+
+func loadTransactions(service *accounts.Service, account string) ([]ledger.Transaction, bool) {
+	from, to, ok := readDateRange()
+	if !ok {
+		return nil, false
+	}
+	transactions, err := service.History(account, from, to)
+	if err != nil {
+		fmt.Println(messages.T("Could not load history:"), messages.Error(err))
+		return nil, false
+	}
+	return transactions, true
+}
+
+func readDateRange() (time.Time, time.Time, bool) {
+	fromText := readLine(messages.T("From (YYYY-MM-DD, empty for any): "))
+	toText := readLine(messages.T("To (YYYY-MM-DD, empty for any): "))
+	from, to, err := dateRange(fromText, toText)
+	if err != nil {
+		fmt.Println(messages.T("Invalid input."), messages.Error(err))
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
+}
+
+// dateRange parses two optional dates into a range covering both days in
+// full. Empty text leaves that side of the range open.
+func dateRange(fromText, toText string) (time.Time, time.Time, error) {
+	from, err := parseDate(fromText)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("expected date as YYYY-MM-DD")
+	}
+	to, err := parseDate(toText)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("expected date as YYYY-MM-DD")
+	}
+	if !to.IsZero() {
+		to = to.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return from, to, nil
+}
+
+func parseDate(text string) (time.Time, error) {
+	if text == "" {
+		return time.Time{}, nil
+	}
+	return time.ParseInLocation(dateLayout, text, time.Local)
+}
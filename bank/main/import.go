@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"example.com/bank/accounts"
+	"example.com/bank/importer"
+	"example.com/bank/ledger"
+	"example.com/bank/messages"
+)
+
+const importProfilesFile = "import_profiles.json"
+const importedFile = "imported.json"
+
+// importStatement posts the rows of a bank CSV export to the account. Rows
+// imported before are skipped; with dryRun only the preview is printed.
+func importStatement(service *accounts.Service, account, fileName, profileName string, dryRun bool) error {
+	profile, err := importer.LoadProfile(importProfilesFile, profileName)
+	if err != nil {
+		return invalidInput(err)
+	}
+	file, err := os.Open(fileName)
+	if err != nil {
+		return invalidInput(errors.New("could not open statement file"))
+	}
+	defer file.Close()
+	rows, err := importer.Parse(file, profile, account)
+	if err != nil {
+		return invalidInput(err)
+	}
+	imported, err := importer.LoadImported(importedFile)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%-5s  %-10s  %12s  %-10s  %-12s  %s\n", messages.T("Line"), messages.T("Date"), messages.T("Amount"),
+		messages.T("Status"), messages.T("Reference"), messages.T("Description"))
+	newRows := []importer.Row{}
+	for _, row := range rows {
+		status := "new"
+		if imported.Contains(row.Hash) {
+			status = "duplicate"
+		} else {
+			newRows = append(newRows, row)
+		}
+		fmt.Printf("%-5d  %-10s  %12s  %-10s  %-12s  %s\n",
+			row.Line, row.Date.Format(dateLayout), row.Amount, messages.T(status), row.Reference, row.Description)
+	}
+	if dryRun {
+		fmt.Print(messages.F("Dry run: %d new, %d duplicate row(s); nothing was posted\n", len(newRows), len(rows)-len(newRows)))
+		return nil
+	}
+
+	var failed error
+	posted := 0
+	for _, row := range newRows {
+		err = imported.Add(row.Hash)
+		if err != nil {
+			return err
+		}
+		err = postRow(service, account, row)
+		if err != nil {
+			fmt.Print(messages.F("Line %d not imported: %s\n", row.Line, messages.Error(err)))
+			failed = errors.Join(failed, err)
+			err = imported.Remove(row.Hash)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		posted++
+	}
+	fmt.Print(messages.F("Imported %d of %d new row(s)\n", posted, len(newRows)))
+	return failed
+}
+
+// postRow posts a statement row at the current time, so the log stays in
+// time order for balances at a point in time. The statement date is kept at
+// the start of the memo.
+func postRow(service *accounts.Service, account string, row importer.Row) error {
+	memo := row.Date.Format(dateLayout) + " " + row.Description
+	if row.Reference != "" {
+		memo += " (ref " + row.Reference + ")"
+	}
+	var err error
+	if row.Amount > 0 {
+		_, err = service.Deposit(account, row.Amount, ledger.Details{Memo: memo})
+	} else {
+		_, err = service.Withdraw(account, -row.Amount, ledger.Details{Memo: memo})
+	}
+	if errors.Is(err, accounts.ErrBalanceNotSaved) {
+		return nil
+	}
+	return err
+}
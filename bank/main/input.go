@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
+	"example.com/bank/accounts"
+	"example.com/bank/messages"
+	"example.com/bank/money"
+	"example.com/bank/prompt"
+)
+
+// Withdrawals and transfers of at least this amount have to be confirmed.
+const largeWithdrawal = money.Money(100000)
+
+var input = prompt.New(os.Stdin, os.Stdout)
+
+// Every change is saved as soon as it is made, so leaving on EOF or Ctrl+C
+// loses nothing; exitOnInterrupt only waits for a write in progress.
+func exitOnInterrupt(service *accounts.Service) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		service.Close()
+		setEcho(true)
+		fmt.Println()
+		goodbye()
+	}()
+}
+
+func goodbye() {
+	fmt.Println(messages.T("Goodbye!"))
+	os.Exit(0)
+}
+
+func readLine(text string) string {
+	line, err := input.Line(text)
+	checkInput(err)
+	return line
+}
+
+// readSecret reads a line without echoing it when standard input is a
+// terminal.
+func readSecret(text string) string {
+	hidden := setEcho(false) == nil
+	line, err := input.Line(text)
+	if hidden {
+		setEcho(true)
+		fmt.Println()
+	}
+	checkInput(err)
+	return line
+}
+
+func setEcho(on bool) error {
+	mode := "-echo"
+	if on {
+		mode = "echo"
+	}
+	command := exec.Command("stty", mode)
+	command.Stdin = os.Stdin
+	return command.Run()
+}
+
+func readChoice() int {
+	return readNumber(messages.T("Your choice: "), 1, menuOptions)
+}
+
+func readNumber(text string, min, max int) int {
+	number, err := input.Int(text, min, max)
+	checkInput(err)
+	return number
+}
+
+func readAmount(text string) money.Money {
+	amount, err := input.Amount(text)
+	checkInput(err)
+	return amount
+}
+
+func confirm(text string) bool {
+	ok, err := input.Confirm(text)
+	checkInput(err)
+	return ok
+}
+
+func checkInput(err error) {
+	if err == nil {
+		return
+	}
+	if !errors.Is(err, prompt.ErrClosed) {
+		fmt.Println(messages.T("Could not read input:"), messages.Error(err))
+	}
+	goodbye()
+}
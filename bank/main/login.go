@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"example.com/bank/accounts"
+	"example.com/bank/auth"
+	"example.com/bank/messages"
+)
+
+const credentialsFile = "credentials.json"
+
+// login asks for the account PIN until it is correct or the account gets
+// locked. current is the account already logged in to, if any. An account
+// without a PIN gets one set first, see mayInitialize.
+func login(service *accounts.Service, credentials auth.Store, account, current string) bool {
+	if !service.Exists(account) {
+		fmt.Println(messages.T("Invalid input. Unknown account"))
+		return false
+	}
+	if !credentials.HasPIN(account) {
+		fmt.Println(messages.T("This account has no PIN yet."))
+		if !mayInitialize(credentials, current) {
+			fmt.Println(messages.T("Switch to it while logged in to set one."))
+			return false
+		}
+		return setPIN(credentials, account)
+	}
+	for {
+		err := credentials.Verify(account, readSecret(messages.T("PIN: ")), time.Now())
+		saveErr := credentials.Save(credentialsFile)
+		if saveErr != nil {
+			fmt.Println(messages.T("Could not save credentials:"), messages.Error(saveErr))
+			return false
+		}
+		if err == nil {
+			return true
+		}
+		fmt.Println(messages.T("Login failed:"), messages.Error(err))
+		if !errors.Is(err, auth.ErrWrongPIN) {
+			return false
+		}
+	}
+}
+
+// mayInitialize reports whether a PIN may be set for an account that has
+// none. Someone logged in has to confirm their own PIN first. Without a login
+// that is only allowed on the first start, before any account has a PIN.
+func mayInitialize(credentials auth.Store, current string) bool {
+	if current == "" {
+		return len(credentials) == 0
+	}
+	err := credentials.Verify(current, readSecret(messages.F("PIN for %s: ", current)), time.Now())
+	saveErr := credentials.Save(credentialsFile)
+	if err == nil {
+		err = saveErr
+	}
+	if err != nil {
+		fmt.Println(messages.T("Could not set PIN:"), messages.Error(err))
+		return false
+	}
+	return true
+}
+
+func setPIN(credentials auth.Store, account string) bool {
+	pin := readSecret(messages.T("New PIN: "))
+	if readSecret(messages.T("Repeat new PIN: ")) != pin {
+		fmt.Println(messages.T("PINs do not match"))
+		return false
+	}
+	err := credentials.SetPIN(account, pin)
+	if err == nil {
+		err = credentials.Save(credentialsFile)
+	}
+	if err != nil {
+		fmt.Println(messages.T("Could not set PIN:"), messages.Error(err))
+		return false
+	}
+	fmt.Println(messages.T("PIN saved"))
+	return true
+}
+
+func changePIN(credentials auth.Store, account string) {
+	err := credentials.Verify(account, readSecret(messages.T("Current PIN: ")), time.Now())
+	saveErr := credentials.Save(credentialsFile)
+	if err == nil {
+		err = saveErr
+	}
+	if err != nil {
+		fmt.Println(messages.T("Could not change PIN:"), messages.Error(err))
+		return
+	}
+	setPIN(credentials, account)
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"example.com/bank/accounts"
+	"example.com/bank/ledger"
+	"example.com/bank/messages"
+	"example.com/bank/schedule"
+)
+
+const ordersFile = "standing_orders.json"
+const schedulerInterval = time.Minute
+const shutdownTimeout = 10 * time.Second
+
+// interrupted returns a context that is done when the process is interrupted
+// or terminated. The daemon and the API server share it, so one Ctrl+C stops
+// both.
+func interrupted() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+// serve runs the API server until ctx is done, then lets requests in
+// progress finish.
+func serve(ctx context.Context, addr string, handler http.Handler) error {
+	server := &http.Server{Addr: addr, Handler: handler}
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		server.Shutdown(shutdown)
+	}()
+	err := server.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// runScheduler does the work that falls due over time: interest accrual and
+// standing orders.
+func runScheduler(service *accounts.Service) {
+	err := service.AccrueInterest(time.Now())
+	if err != nil {
+		fmt.Println(messages.T("Could not accrue interest:"), messages.Error(err))
+	}
+	executions, err := schedule.Run(ordersFile, service, time.Now())
+	for _, execution := range executions {
+		if execution.Err != nil {
+			fmt.Print(messages.F("Standing order #%d due %s failed: %s\n",
+				execution.Order.ID, execution.Due.Format(dateLayout), messages.Error(execution.Err)))
+			continue
+		}
+		fmt.Print(messages.F("Standing order #%d due %s posted: %s %s\n",
+			execution.Order.ID, execution.Due.Format(dateLayout), messages.T(execution.Order.Kind), execution.Order.Amount))
+	}
+	if err != nil {
+		fmt.Println(messages.T("Could not run standing orders:"), messages.Error(err))
+	}
+}
+
+// runDaemon runs the scheduler every schedulerInterval until ctx is done.
+func runDaemon(ctx context.Context, service *accounts.Service) {
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+	for {
+		runScheduler(service)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func manageStandingOrders(account string) {
+	orders, err := schedule.Load(ordersFile)
+	if err != nil {
+		fmt.Println(messages.T("Could not load standing orders:"), messages.Error(err))
+		return
+	}
+	found := false
+	for _, order := range orders {
+		if order.Account == account {
+			fmt.Println(describeOrder(order))
+			found = true
+		}
+	}
+	if !found {
+		fmt.Println(messages.T("No standing orders"))
+	}
+
+	switch strings.ToLower(readLine(messages.T("(a)dd, (d)elete or empty to go back: "))) {
+	case "a":
+		addStandingOrder(account)
+	case "d":
+		id := readNumber(messages.T("Standing order number: "), 1, math.MaxInt)
+		err = schedule.Remove(ordersFile, account, id)
+		if err != nil {
+			fmt.Println(messages.T("Could not delete standing order:"), messages.Error(err))
+			return
+		}
+		fmt.Println(messages.T("Standing order deleted"))
+	}
+}
+
+func addStandingOrder(account string) {
+	kind := strings.ToLower(readLine(messages.T("Kind (deposit/withdrawal/transfer): ")))
+	to := ""
+	if kind == "transfer" {
+		to = readLine(messages.T("Transfer to account: "))
+	}
+	amount := readAmount(messages.T("Amount: "))
+	memo := readLine(messages.T("Memo (optional): "))
+	category := readLine(messages.T("Category (optional): "))
+	every := readNumber(messages.T("Repeat every (number): "), 1, 366)
+	unit := strings.ToLower(readLine(messages.T("Unit (day/week/month): ")))
+	start, err := parseDate(readLine(messages.T("First date (YYYY-MM-DD, empty for today): ")))
+	if err != nil {
+		fmt.Println(messages.T("Invalid input. Expected date as YYYY-MM-DD"))
+		return
+	}
+	if start.IsZero() {
+		now := time.Now()
+		start = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	}
+
+	order, err := schedule.New(kind, account, to, amount, ledger.Details{Memo: memo, Category: category}, every, unit, start)
+	if err == nil {
+		order, err = schedule.Add(ordersFile, order)
+	}
+	if err != nil {
+		fmt.Println(messages.T("Could not add standing order:"), messages.Error(err))
+		return
+	}
+	fmt.Println(messages.T("Standing order added:"), describeOrder(order))
+}
+
+func describeOrder(order schedule.Order) string {
+	target := order.Account
+	if order.Kind == ledger.Transfer {
+		target = order.Account + " -> " + order.To
+	}
+	return messages.F("#%d %s %s %s every %d %s(s), next %s %s",
+		order.ID, messages.T(order.Kind), order.Amount, target, order.Every, messages.T(order.Unit),
+		order.Next.Format(dateLayout), order.Memo)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"example.com/bank/events"
+	"example.com/bank/messages"
+)
+
+// parseMoment reads a date, which means the end of that day, or a date and
+// time. Empty text means now.
+func parseMoment(text string) (time.Time, error) {
+	if text == "" {
+		return time.Time{}, nil
+	}
+	moment, err := time.ParseInLocation("2006-01-02 15:04", text, time.Local)
+	if err == nil {
+		return moment, nil
+	}
+	_, moment, err = dateRange("", text)
+	if err != nil {
+		return time.Time{}, errors.New("expected time as YYYY-MM-DD or YYYY-MM-DD HH:MM")
+	}
+	return moment, nil
+}
+
+func replayLog(until time.Time, asJSON bool) error {
+	list, err := events.Load(".")
+	if err != nil {
+		return err
+	}
+	state := events.Replay(list, until)
+	if asJSON {
+		return writeOutput(true, state, nil)
+	}
+	if state.Seq == 0 {
+		fmt.Println(messages.T("No events before that time"))
+		return nil
+	}
+	fmt.Println(messages.F("State after event #%d of %s", state.Seq, state.Time.Format("2006-01-02 15:04")))
+	for _, account := range state.Accounts {
+		fmt.Printf("%-16s %-10s %12s %s\n", account.Name, account.Product, state.Balances[account.Name], account.Currency)
+	}
+	return nil
+}
+
+func verifyLog(asJSON bool) error {
+	count, err := events.Verify(".")
+	if err != nil {
+		return err
+	}
+	return writeOutput(asJSON, map[string]any{"events": count, "ok": true}, messages.F("Event log OK: %d events", count))
+}
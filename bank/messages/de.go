@@ -0,0 +1,290 @@
+package messages
+
+var german = map[string]string{
+	// Menu
+	"Welcome to %s!":                         "Willkommen bei %s!",
+	"Reach us %s at %s":                      "Sie erreichen uns %s unter %s",
+	"Reach us at %s":                         "Sie erreichen uns unter %s",
+	"Email: %s":                              "E-Mail: %s",
+	"Visit us at %s":                         "Besuchen Sie uns: %s",
+	"What do you want to do? (account: %s)":  "Was möchten Sie tun? (Konto: %s)",
+	"Check balance":                          "Kontostand anzeigen",
+	"Deposit Money":                          "Geld einzahlen",
+	"Withdraw Money":                         "Geld abheben",
+	"Transfer Money":                         "Geld überweisen",
+	"Transaction history":                    "Umsätze",
+	"Export statement":                       "Kontoauszug exportieren",
+	"Open account":                           "Konto eröffnen",
+	"Switch account":                         "Konto wechseln",
+	"Change PIN":                             "PIN ändern",
+	"Standing orders":                        "Daueraufträge",
+	"Net worth":                              "Gesamtvermögen",
+	"Budgets and spending report":            "Budgets und Ausgabenbericht",
+	"Exit":                                   "Beenden",
+	"Your choice: ":                          "Ihre Wahl: ",
+	"Goodbye!":                               "Auf Wiedersehen!",
+	"ERROR:":                                 "FEHLER:",
+	"-----------------------":                "-----------------------",
+	"Could not read input:":                  "Eingabe konnte nicht gelesen werden:",
+	"Account (empty for main): ":             "Konto (leer für main): ",
+	"Your deposit: ":                         "Ihre Einzahlung: ",
+	"How much do you want to withdraw: ":     "Wie viel möchten Sie abheben: ",
+	"Withdraw %s?":                           "%s abheben?",
+	"Withdrawal cancelled":                   "Abhebung abgebrochen",
+	"Transfer to account: ":                  "Überweisen an Konto: ",
+	"Amount: ":                               "Betrag: ",
+	"Transfer %s to %s?":                     "%s an %s überweisen?",
+	"Transfer cancelled":                     "Überweisung abgebrochen",
+	"New account name: ":                     "Name des neuen Kontos: ",
+	"Currency %v: ":                          "Währung %v: ",
+	"Could not open account:":                "Konto konnte nicht eröffnet werden:",
+	"Account opened":                         "Konto eröffnet",
+	"Accounts:":                              "Konten:",
+	"Switch to account: ":                    "Wechseln zu Konto: ",
+	"Transaction failed:":                    "Buchung fehlgeschlagen:",
+	"Warning:":                               "Warnung:",
+	"Your current balance:":                  "Ihr aktueller Kontostand:",
+	"Your balance is":                        "Ihr Kontostand beträgt",
+	"Account type:":                          "Kontoart:",
+	"Account type: ":                         "Kontoart: ",
+	"Overdraft limit: %s (fee %s)\n":         "Dispolimit: %s (Gebühr %s)\n",
+	"Base currency %v: ":                     "Basiswährung %v: ",
+	"Could not compute net worth:":           "Gesamtvermögen konnte nicht berechnet werden:",
+	"- %s (interest %.2f%%, overdraft %s)\n": "- %s (Zinsen %.2f%%, Dispo %s)\n",
+	"Memo (optional): ":                      "Verwendungszweck (optional): ",
+	"Category (optional): ":                  "Kategorie (optional): ",
+	"Tags (comma separated, optional): ":     "Schlagwörter (durch Komma getrennt, optional): ",
+	"Invalid input.":                         "Ungültige Eingabe.",
+	"Invalid input. Unknown format":          "Ungültige Eingabe. Unbekanntes Format",
+	"Invalid input. Unknown account":         "Ungültige Eingabe. Unbekanntes Konto",
+	"Invalid input. Amount must be greater then 0":  "Ungültige Eingabe. Der Betrag muss größer als 0 sein",
+	"Invalid input. Answer y or n":                  "Ungültige Eingabe. Antworten Sie mit j oder n",
+	"Invalid input. Enter a number from %d to %d\n": "Ungültige Eingabe. Geben Sie eine Zahl von %d bis %d ein\n",
+	"Invalid input. Expected date as YYYY-MM-DD":    "Ungültige Eingabe. Datum im Format JJJJ-MM-TT erwartet",
+	" (y/n): ": " (j/n): ",
+	"y,yes":    "j,ja",
+	"n,no":     "n,nein",
+	"Interest rate: %.2f%% per year, accrued this month: %s\n": "Zinssatz: %.2f%% pro Jahr, in diesem Monat aufgelaufen: %s\n",
+
+	// History and statements
+	"No transactions found":              "Keine Umsätze gefunden",
+	"%s  %-10s %10s  balance %10s  %s\n": "%s  %-10s %10s  Saldo %10s  %s\n",
+	"Format (csv/text): ":                "Format (csv/text): ",
+	"Could not create statement file":    "Kontoauszug konnte nicht angelegt werden",
+	"Could not write statement:":         "Kontoauszug konnte nicht geschrieben werden:",
+	"Statement saved to":                 "Kontoauszug gespeichert unter",
+	"Could not load history:":            "Umsätze konnten nicht geladen werden:",
+	"From (YYYY-MM-DD, empty for any): ": "Von (JJJJ-MM-TT, leer für beliebig): ",
+	"To (YYYY-MM-DD, empty for any): ":   "Bis (JJJJ-MM-TT, leer für beliebig): ",
+	"deposit":                            "Einzahlung",
+	"withdrawal":                         "Auszahlung",
+	"transfer":                           "Überweisung",
+	"interest":                           "Zinsen",
+	"fee":                                "Gebühr",
+
+	// Login
+	"This account has no PIN yet.": "Dieses Konto hat noch keine PIN.",
+	"PIN for %s: ":                 "PIN für %s: ",
+	"PIN: ":                        "PIN: ",
+	"Could not save credentials:":  "Zugangsdaten konnten nicht gespeichert werden:",
+	"Login failed:":                "Anmeldung fehlgeschlagen:",
+	"New PIN: ":                    "Neue PIN: ",
+	"Repeat new PIN: ":             "Neue PIN wiederholen: ",
+	"PINs do not match":            "Die PINs stimmen nicht überein",
+	"Could not set PIN:":           "PIN konnte nicht gesetzt werden:",
+	"PIN saved":                    "PIN gespeichert",
+	"Current PIN: ":                "Aktuelle PIN: ",
+	"Could not change PIN:":        "PIN konnte nicht geändert werden:",
+	"Switch to it while logged in to set one.": "Wechseln Sie angemeldet zu diesem Konto, um eine zu setzen.",
+
+	// Standing orders
+	"Could not accrue interest:":                 "Zinsen konnten nicht berechnet werden:",
+	"Standing order #%d due %s failed: %s\n":     "Dauerauftrag #%d fällig am %s fehlgeschlagen: %s\n",
+	"Standing order #%d due %s posted: %s %s\n":  "Dauerauftrag #%d fällig am %s gebucht: %s %s\n",
+	"Could not run standing orders:":             "Daueraufträge konnten nicht ausgeführt werden:",
+	"Could not load standing orders:":            "Daueraufträge konnten nicht geladen werden:",
+	"No standing orders":                         "Keine Daueraufträge",
+	"(a)dd, (d)elete or empty to go back: ":      "(a) hinzufügen, (d) löschen oder leer für zurück: ",
+	"Standing order number: ":                    "Nummer des Dauerauftrags: ",
+	"Could not delete standing order:":           "Dauerauftrag konnte nicht gelöscht werden:",
+	"Standing order deleted":                     "Dauerauftrag gelöscht",
+	"Kind (deposit/withdrawal/transfer): ":       "Art (deposit/withdrawal/transfer): ",
+	"Repeat every (number): ":                    "Wiederholen alle (Anzahl): ",
+	"Unit (day/week/month): ":                    "Einheit (day/week/month): ",
+	"First date (YYYY-MM-DD, empty for today): ": "Erstes Datum (JJJJ-MM-TT, leer für heute): ",
+	"Could not add standing order:":              "Dauerauftrag konnte nicht angelegt werden:",
+	"Standing order added:":                      "Dauerauftrag angelegt:",
+	"#%d %s %s %s every %d %s(s), next %s %s":    "#%d %s %s %s alle %d %s, nächste %s %s",
+	"day":   "Tag(e)",
+	"week":  "Woche(n)",
+	"month": "Monat(e)",
+
+	// Budgets
+	"Budget alert: %s spent %s of %s this month\n": "Budgetwarnung: %s hat diesen Monat %s von %s ausgegeben\n",
+	"(r)eport or (s)et budget: ":                   "(r) Bericht oder (s) Budget festlegen: ",
+	"Number of months: ":                           "Anzahl der Monate: ",
+	"Could not write report:":                      "Bericht konnte nicht erstellt werden:",
+	"Could not load budgets:":                      "Budgets konnten nicht geladen werden:",
+	"Could not save budgets:":                      "Budgets konnten nicht gespeichert werden:",
+	"Category: ":                                   "Kategorie: ",
+	"Monthly limit (empty removes the budget): ":   "Monatliches Limit (leer entfernt das Budget): ",
+	"Budget removed":                               "Budget entfernt",
+	"Budget saved":                                 "Budget gespeichert",
+	"Category":                                     "Kategorie",
+	"Budget":                                       "Budget",
+	"Total":                                        "Summe",
+	"over budget":                                  "über Budget",
+
+	// Import
+	"Line":        "Zeile",
+	"Date":        "Datum",
+	"Amount":      "Betrag",
+	"Status":      "Status",
+	"Reference":   "Referenz",
+	"Description": "Beschreibung",
+	"new":         "neu",
+	"duplicate":   "doppelt",
+	"Dry run: %d new, %d duplicate row(s); nothing was posted\n": "Probelauf: %d neue, %d doppelte Zeile(n); nichts wurde gebucht\n",
+	"Line %d not imported: %s\n":                                 "Zeile %d nicht importiert: %s\n",
+	"Imported %d of %d new row(s)\n":                             "%d von %d neuen Zeile(n) importiert\n",
+
+	// Commands
+	"%s API listening on %s":                          "%s API wartet auf %s",
+	"unknown command %q, see 'bank help'":             "unbekannter Befehl %q, siehe 'bank help'",
+	"expected %d argument(s), got %d":                 "%d Argument(e) erwartet, %d erhalten",
+	"months must be at least 1":                       "es muss mindestens 1 Monat sein",
+	"No events before that time":                      "Keine Ereignisse vor diesem Zeitpunkt",
+	"State after event #%d of %s":                     "Stand nach Ereignis #%d vom %s",
+	"Event log OK: %d events":                         "Ereignisprotokoll in Ordnung: %d Ereignisse",
+	"expected time as YYYY-MM-DD or YYYY-MM-DD HH:MM": "Zeitpunkt im Format JJJJ-MM-TT oder JJJJ-MM-TT HH:MM erwartet",
+	`Usage: bank [command] [flags]
+
+Without a command the interactive menu is started.
+
+Commands:
+  balance                     print the account balance
+  deposit <amount>            deposit money
+  withdraw <amount>           withdraw money
+  transfer <to> <amount>      transfer money to another account
+  history                     list transactions (--from, --to, --json)
+  networth                    print all accounts converted to --base currency
+  report                      spending per category and budgets (--months)
+  import <file.csv>           import a bank statement (--profile, --dry-run)
+  serve                       serve the REST API (--addr, --daemon)
+  daemon                      post standing orders and interest as they fall due
+  replay                      rebuild the balances from the event log (--at)
+  verify                      check the event log and snapshot for tampering
+
+Deposit, withdraw and transfer take --memo, --category and --tags.
+Account commands take --account (default main) and --pin; the PIN can also
+be set in the BANK_PIN environment variable.
+
+--lang (or BANK_LANG) selects the language of the messages: en or de.
+`: `Aufruf: bank [Befehl] [Optionen]
+
+Ohne Befehl wird das interaktive Menü gestartet.
+
+Befehle:
+  balance                     Kontostand ausgeben
+  deposit <Betrag>            Geld einzahlen
+  withdraw <Betrag>           Geld abheben
+  transfer <an> <Betrag>      Geld auf ein anderes Konto überweisen
+  history                     Umsätze auflisten (--from, --to, --json)
+  networth                    alle Konten in der Währung --base ausgeben
+  report                      Ausgaben je Kategorie und Budgets (--months)
+  import <datei.csv>          Kontoauszug importieren (--profile, --dry-run)
+  serve                       REST-API bereitstellen (--addr, --daemon)
+  daemon                      Daueraufträge und Zinsen bei Fälligkeit buchen
+  replay                      Kontostände aus dem Ereignisprotokoll herstellen (--at)
+  verify                      Ereignisprotokoll und Snapshot auf Manipulation prüfen
+
+Deposit, withdraw und transfer nehmen --memo, --category und --tags.
+Kontobefehle nehmen --account (Standard main) und --pin; die PIN kann auch
+in der Umgebungsvariable BANK_PIN gesetzt werden.
+
+--lang (oder BANK_LANG) wählt die Sprache der Meldungen: en oder de.
+`,
+
+	// Errors
+	"account does not exist": "Konto existiert nicht",
+	"account already exists": "Konto existiert bereits",
+	"account name may only contain lowercase letters, digits, '-' and '_'": "Kontoname darf nur Kleinbuchstaben, Ziffern, '-' und '_' enthalten",
+	"amount must be greater then 0":                                        "Betrag muss größer als 0 sein",
+	"insufficient funds":                                                   "Deckung nicht ausreichend",
+	"cannot transfer to the same account":                                  "Überweisung auf dasselbe Konto nicht möglich",
+	"transaction recorded but balance file could not be updated":           "Buchung gespeichert, aber die Kontostandsdatei konnte nicht aktualisiert werden",
+	"account product does not exist":                                       "Kontoart existiert nicht",
+	"wrong PIN":                                                            "falsche PIN",
+	"account is locked after too many failed attempts":                     "Konto nach zu vielen Fehlversuchen gesperrt",
+	"account has no PIN":                                                   "Konto hat keine PIN",
+	"PIN must have between 4 and 12 digits":                                "PIN muss zwischen 4 und 12 Ziffern haben",
+	"PIN may only contain digits":                                          "PIN darf nur Ziffern enthalten",
+	"currency is not in the exchange rate table":                           "Währung ist nicht in der Wechselkurstabelle",
+	"exchange rates must be greater then 0":                                "Wechselkurse müssen größer als 0 sein",
+	"exchange fee must be at least 0 and below 100 percent":                "Wechselgebühr muss mindestens 0 und unter 100 Prozent sein",
+	"invalid amount":                                                       "ungültiger Betrag",
+	"amount can have at most 2 decimal places":                             "Betrag darf höchstens 2 Nachkommastellen haben",
+	"amount is too large":                                                  "Betrag ist zu groß",
+	"amount is zero":                                                       "Betrag ist null",
+	"expected date as YYYY-MM-DD":                                          "Datum im Format JJJJ-MM-TT erwartet",
+	"dates must be formatted as YYYY-MM-DD":                                "Datumsangaben müssen das Format JJJJ-MM-TT haben",
+	"standing order does not exist":                                        "Dauerauftrag existiert nicht",
+	"kind must be deposit, withdrawal or transfer":                         "Art muss deposit, withdrawal oder transfer sein",
+	"transfer needs a target account":                                      "Überweisung braucht ein Zielkonto",
+	"interval must be greater then 0":                                      "Intervall muss größer als 0 sein",
+	"unit must be day, week or month":                                      "Einheit muss day, week oder month sein",
+	"could not open statement file":                                        "Kontoauszug konnte nicht geöffnet werden",
+	"unknown language":                                                     "unbekannte Sprache",
+	"input closed":                                                         "Eingabe geschlossen",
+	"file not found":                                                       "Datei nicht gefunden",
+	"file is truncated":                                                    "Datei ist abgeschnitten",
+	"checksum mismatch":                                                    "Prüfsumme stimmt nicht",
+	"faild to parse balance":                                               "Kontostand konnte nicht gelesen werden",
+	"not configured":                                                       "nicht konfiguriert",
+	"failed to read accounts file":                                         "Kontendatei konnte nicht gelesen werden",
+	"failed to parse accounts file":                                        "Kontendatei ist ungültig",
+	"failed to convert accounts to json":                                   "Konten konnten nicht in JSON umgewandelt werden",
+	"failed to read products file":                                         "Kontoartendatei konnte nicht gelesen werden",
+	"failed to parse products file":                                        "Kontoartendatei ist ungültig",
+	"failed to read credentials file":                                      "Zugangsdatendatei konnte nicht gelesen werden",
+	"failed to parse credentials file":                                     "Zugangsdatendatei ist ungültig",
+	"failed to convert credentials to json":                                "Zugangsdaten konnten nicht in JSON umgewandelt werden",
+	"failed to generate salt":                                              "Salt konnte nicht erzeugt werden",
+	"failed to derive PIN hash":                                            "PIN-Hash konnte nicht berechnet werden",
+	"failed to read budgets file":                                          "Budgetdatei konnte nicht gelesen werden",
+	"failed to parse budgets file":                                         "Budgetdatei ist ungültig",
+	"failed to convert budgets to json":                                    "Budgets konnten nicht in JSON umgewandelt werden",
+	"failed to read config file":                                           "Konfigurationsdatei konnte nicht gelesen werden",
+	"failed to parse config file":                                          "Konfigurationsdatei ist ungültig",
+	"failed to read exchange rates file":                                   "Wechselkursdatei konnte nicht gelesen werden",
+	"failed to parse exchange rates file":                                  "Wechselkursdatei ist ungültig",
+	"failed to create temporary file":                                      "temporäre Datei konnte nicht angelegt werden",
+	"failed to write temporary file":                                       "temporäre Datei konnte nicht geschrieben werden",
+	"failed to set file permissions":                                       "Dateirechte konnten nicht gesetzt werden",
+	"failed to replace file":                                               "Datei konnte nicht ersetzt werden",
+	"failed to read import profiles file":                                  "Importprofildatei konnte nicht gelesen werden",
+	"failed to parse import profiles file":                                 "Importprofildatei ist ungültig",
+	"failed to read imported rows file":                                    "Datei der importierten Zeilen konnte nicht gelesen werden",
+	"failed to parse imported rows file":                                   "Datei der importierten Zeilen ist ungültig",
+	"failed to convert imported rows to json":                              "importierte Zeilen konnten nicht in JSON umgewandelt werden",
+	"failed to read csv":                                                   "CSV konnte nicht gelesen werden",
+	"failed to open history file":                                          "Umsatzdatei konnte nicht geöffnet werden",
+	"failed to read history file":                                          "Umsatzdatei konnte nicht gelesen werden",
+	"failed to repair history file":                                        "Umsatzdatei konnte nicht repariert werden",
+	"failed to parse history file":                                         "Umsatzdatei ist ungültig",
+	"failed to read standing orders file":                                  "Dauerauftragsdatei konnte nicht gelesen werden",
+	"failed to parse standing orders file":                                 "Dauerauftragsdatei ist ungültig",
+	"failed to convert standing orders to json":                            "Daueraufträge konnten nicht in JSON umgewandelt werden",
+	"event log was modified":                                               "Ereignisprotokoll wurde verändert",
+	"event log is truncated":                                               "Ereignisprotokoll ist abgeschnitten",
+	"snapshot does not match the event log":                                "Snapshot passt nicht zum Ereignisprotokoll",
+	"failed to open event log":                                             "Ereignisprotokoll konnte nicht geöffnet werden",
+	"failed to read event log":                                             "Ereignisprotokoll konnte nicht gelesen werden",
+	"failed to repair event log":                                           "Ereignisprotokoll konnte nicht repariert werden",
+	"failed to write event":                                                "Ereignis konnte nicht geschrieben werden",
+	"failed to sync event log":                                             "Ereignisprotokoll konnte nicht gesichert werden",
+	"failed to convert event to json":                                      "Ereignis konnte nicht in JSON umgewandelt werden",
+	"failed to convert head to json":                                       "Protokollkopf konnte nicht in JSON umgewandelt werden",
+	"failed to convert snapshot to json":                                   "Snapshot konnte nicht in JSON umgewandelt werden",
+	"failed to rename history file":                                        "Umsatzdatei konnte nicht umbenannt werden",
+}
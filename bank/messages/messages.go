@@ -0,0 +1,81 @@
+package messages
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const Default = "en"
+
+var ErrUnknownLanguage = errors.New("unknown language")
+
+// Catalogs map the English text to its translation. English needs no
+// catalog as the text is used as is.
+var catalogs = map[string]map[string]string{
+	"en": {},
+	"de": german,
+}
+
+var current = catalogs[Default]
+
+func Languages() []string {
+	languages := []string{}
+	for language := range catalogs {
+		languages = append(languages, language)
+	}
+	slices.Sort(languages)
+	return languages
+}
+
+// SetLanguage selects the catalog used by T. Locale names like "de_DE.UTF-8"
+// select their language.
+func SetLanguage(language string) error {
+	language = strings.ToLower(strings.TrimSpace(language))
+	language, _, _ = strings.Cut(language, ".")
+	language, _, _ = strings.Cut(language, "_")
+	language, _, _ = strings.Cut(language, "-")
+	catalog, ok := catalogs[language]
+	if !ok {
+		return ErrUnknownLanguage
+	}
+	current = catalog
+	return nil
+}
+
+// T returns text in the selected language, or text itself when it has no
+// translation.
+func T(text string) string {
+	translation, ok := current[text]
+	if !ok {
+		return text
+	}
+	return translation
+}
+
+// F translates the format before formatting the arguments with it.
+func F(format string, args ...any) string {
+	return fmt.Sprintf(T(format), args...)
+}
+
+// Error translates an error message. Errors wrapped as "context: cause" or
+// joined on separate lines are translated part by part.
+func Error(err error) string {
+	if err == nil {
+		return ""
+	}
+	text := err.Error()
+	if translation, ok := current[text]; ok {
+		return translation
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		parts := strings.Split(line, ": ")
+		for j, part := range parts {
+			parts[j] = T(part)
+		}
+		lines[i] = strings.Join(parts, ": ")
+	}
+	return strings.Join(lines, "\n")
+}
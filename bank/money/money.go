@@ -0,0 +1,111 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money is an amount in minor units (cents), so sums never drift the way
+// float64 values do.
+type Money int64
+
+const unitsPerMajor = 100
+
+// Parse reads an amount such as "12", "12.5" or "-12.34". More than two
+// decimal places is an error rather than being rounded silently.
+func Parse(text string) (Money, error) {
+	text = strings.TrimSpace(text)
+	negative := strings.HasPrefix(text, "-")
+	text = strings.TrimPrefix(strings.TrimPrefix(text, "-"), "+")
+
+	whole, fraction, _ := strings.Cut(text, ".")
+	if whole == "" && fraction == "" {
+		return 0, errors.New("invalid amount")
+	}
+	if len(fraction) > 2 {
+		return 0, errors.New("amount can have at most 2 decimal places")
+	}
+	for _, digits := range []string{whole, fraction} {
+		if strings.Trim(digits, "0123456789") != "" {
+			return 0, errors.New("invalid amount")
+		}
+	}
+	fraction += strings.Repeat("0", 2-len(fraction))
+	if whole == "" {
+		whole = "0"
+	}
+
+	major, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || major > math.MaxInt64/unitsPerMajor-1 {
+		return 0, errors.New("amount is too large")
+	}
+	minor, _ := strconv.ParseInt(fraction, 10, 64)
+	value := Money(major*unitsPerMajor + minor)
+	if negative {
+		value = -value
+	}
+	return value, nil
+}
+
+// FromFloat rounds a float64 to the nearest minor unit. It is meant for
+// reading values stored before balances were kept as Money.
+func FromFloat(value float64) Money {
+	return Money(math.Round(value * unitsPerMajor))
+}
+
+// ParseStored reads amounts written by older versions, which stored plain
+// float64 text such as "100.30000000000001". It is only meant for migrating
+// those files; everything else goes through Parse.
+func ParseStored(text string) (Money, error) {
+	value, err := Parse(text)
+	if err == nil {
+		return value, nil
+	}
+	float, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+	if err != nil || math.IsNaN(float) || math.Abs(float) >= math.MaxInt64/unitsPerMajor {
+		return 0, errors.New("invalid amount")
+	}
+	return FromFloat(float), nil
+}
+
+func (m Money) String() string {
+	sign := ""
+	units := int64(m)
+	if units < 0 {
+		sign = "-"
+		units = -units
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, units/unitsPerMajor, units%unitsPerMajor)
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts a number or a string with at most two decimal
+// places, like Parse.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	value, err := Parse(strings.Trim(string(data), `"`))
+	if err != nil {
+		return err
+	}
+	*m = value
+	return nil
+}
+
+// Scan lets fmt.Scan and friends read Money directly from user input.
+func (m *Money) Scan(state fmt.ScanState, verb rune) error {
+	token, err := state.Token(true, nil)
+	if err != nil {
+		return err
+	}
+	value, err := Parse(string(token))
+	if err != nil {
+		return err
+	}
+	*m = value
+	return nil
+}
@@ -0,0 +1,103 @@
+package prompt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"example.com/bank/messages"
+	"example.com/bank/money"
+)
+
+// ErrClosed is returned once the input reached EOF.
+var ErrClosed = errors.New("input closed")
+
+// Prompter reads answers line by line and asks again until the answer is
+// valid, so a typo never leaves unread input behind for the next question.
+type Prompter struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+}
+
+func New(in io.Reader, out io.Writer) *Prompter {
+	return &Prompter{
+		scanner: bufio.NewScanner(in),
+		out:     out,
+	}
+}
+
+func (p *Prompter) Line(prompt string) (string, error) {
+	fmt.Fprint(p.out, prompt)
+	if !p.scanner.Scan() {
+		fmt.Fprintln(p.out)
+		if p.scanner.Err() != nil {
+			return "", p.scanner.Err()
+		}
+		return "", ErrClosed
+	}
+	return strings.TrimSpace(p.scanner.Text()), nil
+}
+
+// Int asks until the answer is a whole number between min and max.
+func (p *Prompter) Int(prompt string, min, max int) (int, error) {
+	for {
+		text, err := p.Line(prompt)
+		if err != nil {
+			return 0, err
+		}
+		value, err := strconv.Atoi(text)
+		if err == nil && value >= min && value <= max {
+			return value, nil
+		}
+		fmt.Fprint(p.out, messages.F("Invalid input. Enter a number from %d to %d\n", min, max))
+	}
+}
+
+// Amount asks until the answer is a positive amount of money.
+func (p *Prompter) Amount(prompt string) (money.Money, error) {
+	for {
+		text, err := p.Line(prompt)
+		if err != nil {
+			return 0, err
+		}
+		amount, err := money.Parse(text)
+		if err != nil {
+			fmt.Fprintln(p.out, messages.T("Invalid input."), messages.Error(err))
+			continue
+		}
+		if amount <= 0 {
+			fmt.Fprintln(p.out, messages.T("Invalid input. Amount must be greater then 0"))
+			continue
+		}
+		return amount, nil
+	}
+}
+
+// Confirm asks a yes/no question until the answer is one of y, yes, n or no,
+// or their translation.
+func (p *Prompter) Confirm(prompt string) (bool, error) {
+	for {
+		text, err := p.Line(prompt + messages.T(" (y/n): "))
+		if err != nil {
+			return false, err
+		}
+		text = strings.ToLower(text)
+		if slices.Contains(answers("y,yes"), text) {
+			return true, nil
+		}
+		if slices.Contains(answers("n,no"), text) {
+			return false, nil
+		}
+		fmt.Fprintln(p.out, messages.T("Invalid input. Answer y or n"))
+	}
+}
+
+// answers lists the accepted answers, in English and in the selected
+// language.
+func answers(english string) []string {
+	return append(strings.Split(english, ","), strings.Split(messages.T(english), ",")...)
+}
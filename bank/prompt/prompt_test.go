@@ -0,0 +1,103 @@
+package prompt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"example.com/bank/money"
+)
+
+func TestInt(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		want     int
+		err      error
+		reprompt int
+	}{
+		{"valid", "3\n", 3, nil, 0},
+		{"surrounding spaces", "  2 \n", 2, nil, 0},
+		{"reprompt on text", "abc\n4\n", 4, nil, 1},
+		{"reprompt out of range", "0\n9\n-1\n5\n", 5, nil, 3},
+		{"EOF before answer", "", 0, ErrClosed, 0},
+		{"EOF after invalid answer", "x\n", 0, ErrClosed, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out strings.Builder
+			got, err := New(strings.NewReader(test.input), &out).Int("Choice: ", 1, 5)
+			if got != test.want || !errors.Is(err, test.err) {
+				t.Errorf("Int() = %d, %v, want %d, %v", got, err, test.want, test.err)
+			}
+			if count := strings.Count(out.String(), "Invalid input"); count != test.reprompt {
+				t.Errorf("reprompted %d times, want %d:\n%s", count, test.reprompt, out.String())
+			}
+		})
+	}
+}
+
+func TestAmount(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  money.Money
+		err   error
+	}{
+		{"valid", "12.34\n", 1234, nil},
+		{"reprompt on too many decimals", "1.234\n1.23\n", 123, nil},
+		{"reprompt on zero and negative", "0\n-5\n7\n", 700, nil},
+		{"EOF mid-prompt", "abc\n", 0, ErrClosed},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out strings.Builder
+			got, err := New(strings.NewReader(test.input), &out).Amount("Amount: ")
+			if got != test.want || !errors.Is(err, test.err) {
+				t.Errorf("Amount() = %s, %v, want %s, %v", got, err, test.want, test.err)
+			}
+		})
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+		err   error
+	}{
+		{"y", "y\n", true, nil},
+		{"yes in capitals", "YES\n", true, nil},
+		{"n", "n\n", false, nil},
+		{"no", "no\n", false, nil},
+		{"reprompt until answered", "maybe\n\nyes\n", true, nil},
+		{"EOF", "", false, ErrClosed},
+		{"EOF after invalid answer", "maybe\n", false, ErrClosed},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out strings.Builder
+			got, err := New(strings.NewReader(test.input), &out).Confirm("Proceed?")
+			if got != test.want || !errors.Is(err, test.err) {
+				t.Errorf("Confirm() = %t, %v, want %t, %v", got, err, test.want, test.err)
+			}
+		})
+	}
+}
+
+func TestPromptsReadOneLineEach(t *testing.T) {
+	prompter := New(strings.NewReader("oops\n2\n10\nn\n"), &strings.Builder{})
+	choice, err := prompter.Int("Choice: ", 1, 3)
+	if err != nil || choice != 2 {
+		t.Fatalf("Int() = %d, %v", choice, err)
+	}
+	amount, err := prompter.Amount("Amount: ")
+	if err != nil || amount != 1000 {
+		t.Fatalf("Amount() = %s, %v", amount, err)
+	}
+	confirmed, err := prompter.Confirm("Proceed?")
+	if err != nil || confirmed {
+		t.Fatalf("Confirm() = %t, %v", confirmed, err)
+	}
+}
@@ -0,0 +1,199 @@
+package schedule
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"example.com/bank/accounts"
+	"example.com/bank/fileutils"
+	"example.com/bank/ledger"
+	"example.com/bank/money"
+)
+
+const (
+	Day   = "day"
+	Week  = "week"
+	Month = "month"
+)
+
+var ErrUnknownOrder = errors.New("standing order does not exist")
+
+// Order is a standing order: a deposit, withdrawal or transfer repeated every
+// Every units from Start. Occurrence counts the occurrences posted so far and
+// Next is the date of the following one.
+type Order struct {
+	ID         int         `json:"id"`
+	Kind       string      `json:"kind"`
+	Account    string      `json:"account"`
+	To         string      `json:"to,omitempty"`
+	Amount     money.Money `json:"amount"`
+	Memo       string      `json:"memo,omitempty"`
+	Category   string      `json:"category,omitempty"`
+	Every      int         `json:"every"`
+	Unit       string      `json:"unit"`
+	Start      time.Time   `json:"start"`
+	Occurrence int         `json:"occurrence"`
+	Next       time.Time   `json:"next"`
+}
+
+type Execution struct {
+	Order Order
+	Due   time.Time
+	Err   error
+}
+
+func New(kind, account, to string, amount money.Money, details ledger.Details, every int, unit string, start time.Time) (Order, error) {
+	if kind != ledger.Deposit && kind != ledger.Withdrawal && kind != ledger.Transfer {
+		return Order{}, errors.New("kind must be deposit, withdrawal or transfer")
+	}
+	if kind == ledger.Transfer && to == "" {
+		return Order{}, errors.New("transfer needs a target account")
+	}
+	if amount <= 0 {
+		return Order{}, accounts.ErrInvalidAmount
+	}
+	if every <= 0 {
+		return Order{}, errors.New("interval must be greater then 0")
+	}
+	if unit != Day && unit != Week && unit != Month {
+		return Order{}, errors.New("unit must be day, week or month")
+	}
+	if kind != ledger.Transfer {
+		to = ""
+	}
+	return Order{
+		Kind:     kind,
+		Account:  account,
+		To:       to,
+		Amount:   amount,
+		Memo:     details.Memo,
+		Category: details.Category,
+		Every:    every,
+		Unit:     unit,
+		Start:    start,
+		Next:     start,
+	}, nil
+}
+
+// occurrence returns the date of the nth occurrence, counting from 0. Each
+// date is computed from the start, so a monthly order started on the 31st
+// falls on the last day of shorter months and returns to the 31st after.
+func (order Order) occurrence(n int) time.Time {
+	switch order.Unit {
+	case Week:
+		return order.Start.AddDate(0, 0, 7*order.Every*n)
+	case Month:
+		start := order.Start
+		first := time.Date(start.Year(), start.Month()+time.Month(order.Every*n), 1,
+			start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+		lastDay := first.AddDate(0, 1, -1).Day()
+		return first.AddDate(0, 0, min(start.Day(), lastDay)-1)
+	default:
+		return order.Start.AddDate(0, 0, order.Every*n)
+	}
+}
+
+func Load(fileName string) ([]Order, error) {
+	data, err := os.ReadFile(fileName)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Order{}, nil
+	}
+	if err != nil {
+		return nil, errors.New("failed to read standing orders file")
+	}
+	var orders []Order
+	err = json.Unmarshal(data, &orders)
+	if err != nil {
+		return nil, errors.New("failed to parse standing orders file")
+	}
+	return orders, nil
+}
+
+func Save(fileName string, orders []Order) error {
+	data, err := json.MarshalIndent(orders, "", "  ")
+	if err != nil {
+		return errors.New("failed to convert standing orders to json")
+	}
+	return fileutils.WriteAtomic(fileName, data)
+}
+
+func Add(fileName string, order Order) (Order, error) {
+	orders, err := Load(fileName)
+	if err != nil {
+		return Order{}, err
+	}
+	for _, existing := range orders {
+		order.ID = max(order.ID, existing.ID)
+	}
+	order.ID++
+	return order, Save(fileName, append(orders, order))
+}
+
+func Remove(fileName string, account string, id int) error {
+	orders, err := Load(fileName)
+	if err != nil {
+		return err
+	}
+	index := slices.IndexFunc(orders, func(order Order) bool {
+		return order.ID == id && order.Account == account
+	})
+	if index < 0 {
+		return ErrUnknownOrder
+	}
+	return Save(fileName, slices.Delete(orders, index, index+1))
+}
+
+// Run posts every occurrence that fell due up to now, including the ones
+// missed while the program was not running. The order is advanced and saved
+// before each posting, so a crash can skip an occurrence but never post it
+// twice. Failed postings (e.g. insufficient funds) are skipped as well and
+// reported in the returned executions.
+func Run(fileName string, service *accounts.Service, now time.Time) ([]Execution, error) {
+	orders, err := Load(fileName)
+	if err != nil {
+		return nil, err
+	}
+	executions := []Execution{}
+	for i := range orders {
+		for !orders[i].Next.After(now) {
+			due := orders[i].Next
+			orders[i].Occurrence++
+			orders[i].Next = orders[i].occurrence(orders[i].Occurrence)
+			err = Save(fileName, orders)
+			if err != nil {
+				return executions, err
+			}
+			executions = append(executions, Execution{
+				Order: orders[i],
+				Due:   due,
+				Err:   post(service, orders[i], due),
+			})
+		}
+	}
+	return executions, nil
+}
+
+func post(service *accounts.Service, order Order, due time.Time) error {
+	memo := fmt.Sprintf("standing order #%d due %s", order.ID, due.Format("2006-01-02"))
+	if order.Memo != "" {
+		memo = order.Memo + " (" + memo + ")"
+	}
+	details := ledger.Details{Memo: memo, Category: order.Category}
+	var err error
+	switch order.Kind {
+	case ledger.Deposit:
+		_, err = service.Deposit(order.Account, order.Amount, details)
+	case ledger.Withdrawal:
+		_, err = service.Withdraw(order.Account, order.Amount, details)
+	case ledger.Transfer:
+		_, err = service.Transfer(order.Account, order.To, order.Amount, details)
+	}
+	if errors.Is(err, accounts.ErrBalanceNotSaved) {
+		return nil
+	}
+	return err
+}
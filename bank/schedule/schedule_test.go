@@ -0,0 +1,52 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOccurrence(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 9, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name  string
+		order Order
+		want  []time.Time
+	}{
+		{
+			"monthly from the 31st",
+			Order{Every: 1, Unit: Month, Start: date(2025, time.January, 31)},
+			[]time.Time{date(2025, time.January, 31), date(2025, time.February, 28), date(2025, time.March, 31), date(2025, time.April, 30), date(2025, time.May, 31)},
+		},
+		{
+			"leap year February",
+			Order{Every: 1, Unit: Month, Start: date(2024, time.January, 30)},
+			[]time.Time{date(2024, time.January, 30), date(2024, time.February, 29), date(2024, time.March, 30)},
+		},
+		{
+			"every three months across the year end",
+			Order{Every: 3, Unit: Month, Start: date(2025, time.November, 30)},
+			[]time.Time{date(2025, time.November, 30), date(2026, time.February, 28), date(2026, time.May, 30)},
+		},
+		{
+			"every two weeks",
+			Order{Every: 2, Unit: Week, Start: date(2025, time.December, 22)},
+			[]time.Time{date(2025, time.December, 22), date(2026, time.January, 5), date(2026, time.January, 19)},
+		},
+		{
+			"daily",
+			Order{Every: 1, Unit: Day, Start: date(2025, time.February, 28)},
+			[]time.Time{date(2025, time.February, 28), date(2025, time.March, 1)},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for n, want := range test.want {
+				if got := test.order.occurrence(n); !got.Equal(want) {
+					t.Errorf("occurrence(%d) = %s, want %s", n, got.Format(time.DateOnly), want.Format(time.DateOnly))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,452 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"example.com/notes/note"
+	"example.com/notes/search"
+	"example.com/notes/todo"
+)
+
+// Exit codes of the non-interactive commands.
+const (
+	exitOK           = 0
+	exitError        = 1
+	exitInvalidInput = 2
+	exitNotFound     = 3
+	exitAuthFailed   = 4
+)
+
+const usage = `Usage: notes [command] [flags]
+       notes todo [command] [flags]
+
+Without a command notes and todos are managed interactively.
+
+Commands:
+  new [TITLE]          create a note (--title, --content, --tags, --notebook)
+  list                 list notes (--tag, --notebook)
+  show ID              print a note
+  edit ID              change a note (--title, --content, --tags, --notebook);
+                       without flags the content is opened in $EDITOR
+  rm ID                delete a note
+  find QUERY           search notes and todos
+  history ID           list the revisions of a note
+  diff ID FROM [TO]    compare two revisions, or one with the current note
+  restore ID REVISION  make an old revision the current version
+  prune                drop old revisions (--keep, default 50, --days)
+  encrypt [DIR]        encrypt the notes and their revisions at rest
+  export PATH          export all notes as Markdown files with front matter,
+                       as a static HTML site (--html) or, when PATH ends in
+                       .zip, .tar, .tar.gz or .tgz, as a single archive
+  import PATH...       import Markdown files, directories of them or archives
+  decrypt [DIR]        store the notes as plain JSON again
+
+Todo commands:
+  add TEXT             add a todo (--priority, --due YYYY-MM-DD [HH:MM],
+                       --remind YYYY-MM-DD HH:MM or a duration before due)
+  done ID              mark a todo as done
+  reopen ID            mark a todo as open again
+  rm ID                delete a todo
+  ls [FILTERS]         list todos: open, done, a priority, due:YYYY-MM-DD or
+                       a word of the text
+  agenda               list open todos as overdue, today, this week and later
+  remind               notify when todos fall due or reminders come up,
+                       checking --every minute (default 1m); --out appends
+                       the notifications to a file, --once checks once
+
+Notes are referred to by ID or title. Without --content, new reads the note
+content from standard input. All commands take --json to print JSON.
+Run as "todo" the todo commands are available directly.
+
+The passphrase of encrypted notes is asked for, or read from
+NOTES_PASSPHRASE. Todos are not encrypted.
+`
+
+type commandError struct {
+	code int
+	err  error
+}
+
+func (e commandError) Error() string {
+	return e.err.Error()
+}
+
+func invalidInput(err error) error {
+	return commandError{exitInvalidInput, err}
+}
+
+// noteList and todoList display several notes or todos, one per line.
+type noteList []note.Note
+
+func (notes noteList) Display() {
+	printNotes(notes)
+}
+
+type todoList []todo.Todo
+
+func (todos todoList) Display() {
+	if len(todos) == 0 {
+		fmt.Println("No todos")
+	}
+	for _, item := range todos {
+		item.Display()
+	}
+}
+
+type resultList []search.Result
+
+func (results resultList) Display() {
+	printResults(results)
+}
+
+// runCommand runs a command given on the command line and returns the exit
+// code. A binary named todo runs the todo commands.
+func runCommand(name string, args []string) int {
+	var err error
+	if strings.TrimSuffix(filepath.Base(name), ".exe") == "todo" {
+		err = dispatchTodo(args)
+	} else if args[0] == "todo" {
+		err = dispatchTodo(args[1:])
+	} else {
+		err = dispatchNote(args[0], args[1:])
+	}
+	if err == nil {
+		return exitOK
+	}
+	fmt.Fprintln(os.Stderr, "notes:", err)
+
+	var command commandError
+	switch {
+	case errors.As(err, &command):
+		return command.code
+	case errors.Is(err, note.ErrNotFound), errors.Is(err, note.ErrNoRevision), errors.Is(err, todo.ErrNotFound):
+		return exitNotFound
+	case errors.Is(err, note.ErrWrongPassphrase), errors.Is(err, note.ErrNoPassphrase):
+		return exitAuthFailed
+	case errors.Is(err, note.ErrAmbiguous):
+		return exitInvalidInput
+	default:
+		return exitError
+	}
+}
+
+func dispatchNote(command string, args []string) error {
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	title := flags.String("title", "", "note title")
+	content := flags.String("content", "", "note content")
+	tags := flags.String("tags", "", "comma separated tags")
+	notebook := flags.String("notebook", "", "notebook")
+	tag := flags.String("tag", "", "only list notes with this tag")
+	keep := flags.Int("keep", note.DefaultRetention.Keep, "revisions to keep per note")
+	days := flags.Int("days", 0, "drop revisions older than this many days")
+	asHTML := flags.Bool("html", false, "export a static HTML site")
+	asJSON := flags.Bool("json", false, "print JSON")
+
+	if command == "help" || command == "-h" || command == "--help" {
+		fmt.Print(usage)
+		return nil
+	}
+	switch command {
+	case "new", "list", "show", "edit", "rm", "find", "history", "diff", "restore", "prune", "encrypt", "decrypt",
+		"export", "import":
+	default:
+		return invalidInput(fmt.Errorf("unknown command %q, see 'notes help'", command))
+	}
+	positional, err := parseInterspersed(flags, args)
+	if err != nil {
+		return invalidInput(err)
+	}
+	switch command {
+	case "find":
+		return findCommand(strings.Join(positional, " "), *asJSON)
+	case "encrypt", "decrypt":
+		if len(positional) > 1 {
+			return invalidInput(fmt.Errorf("usage: %s [DIR]", command))
+		}
+		dir := note.Dir
+		if len(positional) == 1 {
+			dir = positional[0]
+		}
+		return encryptNotes(dir, command == "encrypt")
+	}
+	store, err := note.Open(note.Dir)
+	if err != nil {
+		return err
+	}
+	reference := strings.Join(positional, " ")
+	var numbers []int
+	if command == "diff" || command == "restore" {
+		reference, numbers, err = revisionArgs(positional, revisionCount(command))
+		if err != nil {
+			return invalidInput(err)
+		}
+	}
+
+	switch command {
+	case "export":
+		if len(positional) != 1 {
+			return invalidInput(errors.New("usage: export PATH"))
+		}
+		result, err := exportNotes(store, positional[0], *asHTML)
+		if err != nil {
+			return err
+		}
+		return writeOutput(result, *asJSON)
+	case "import":
+		if len(positional) == 0 {
+			return invalidInput(errors.New("usage: import PATH..."))
+		}
+		result, err := importNotes(store, positional)
+		if err != nil {
+			return err
+		}
+		return writeOutput(result, *asJSON)
+	case "prune":
+		return store.Prune(note.Retention{Keep: *keep, MaxAge: time.Duration(*days) * 24 * time.Hour})
+	case "new":
+		if *title == "" {
+			*title = reference
+		}
+		if !isSet(flags, "content") {
+			if note.IsTerminal(os.Stdin) {
+				fmt.Fprintln(os.Stderr, "Enter the content, then press Ctrl+D on an empty line:")
+			}
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return errors.New("failed to read note content")
+			}
+			*content = strings.TrimSpace(string(data))
+		}
+		created, err := note.New(*title, *content)
+		if err != nil {
+			return invalidInput(err)
+		}
+		created.Tags = note.ParseTags(*tags)
+		created.Notebook = strings.TrimSpace(*notebook)
+		return saveOutput(&created, *asJSON)
+	case "list":
+		notes, err := store.List()
+		if err != nil {
+			return err
+		}
+		if *tag != "" {
+			notes = note.Tagged(notes, *tag)
+		}
+		if *notebook != "" {
+			notes = note.InNotebook(notes, *notebook)
+		}
+		return writeOutput(noteList(notes), *asJSON)
+	}
+
+	if reference == "" {
+		return invalidInput(errors.New("expected a note ID or title"))
+	}
+	found, err := store.Find(reference)
+	if err != nil {
+		return err
+	}
+	switch command {
+	case "show":
+		return writeOutput(found, *asJSON)
+	case "history":
+		revisions, err := store.History(found.ID)
+		if err != nil {
+			return err
+		}
+		return writeOutput(revisionList(revisions), *asJSON)
+	case "diff":
+		diff, err := diffRevisions(store, found, numbers)
+		if err != nil {
+			return err
+		}
+		return writeOutput(diff, *asJSON)
+	case "restore":
+		if len(numbers) != 1 {
+			return invalidInput(errors.New("usage: restore ID REVISION"))
+		}
+		restored, err := store.Restore(found.ID, numbers[0])
+		if err != nil {
+			return err
+		}
+		return writeOutput(restored, *asJSON)
+	case "rm":
+		err = store.Delete(found.ID)
+		if err != nil {
+			return err
+		}
+		return writeOutput(found, *asJSON)
+	default:
+		changed := false
+		if isSet(flags, "title") {
+			found.Title, changed = *title, true
+		}
+		if isSet(flags, "content") {
+			found.Content, changed = *content, true
+		}
+		if isSet(flags, "tags") {
+			found.Tags, changed = note.ParseTags(*tags), true
+		}
+		if isSet(flags, "notebook") {
+			found.Notebook, changed = strings.TrimSpace(*notebook), true
+		}
+		if !changed {
+			found.Content, err = editContent(found.Content)
+			if err != nil {
+				return err
+			}
+		}
+		if found.Title == "" || found.Content == "" {
+			return invalidInput(errors.New("invalid note data"))
+		}
+		return saveOutput(&found, *asJSON)
+	}
+}
+
+func dispatchTodo(args []string) error {
+	if len(args) == 0 {
+		return invalidInput(errors.New("expected a todo command, see 'notes help'"))
+	}
+	command := args[0]
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	priority := flags.String("priority", todo.Normal, "priority: low, normal or high")
+	dueText := flags.String("due", "", "due date (YYYY-MM-DD [HH:MM])")
+	remindText := flags.String("remind", "", "reminder time or duration before due")
+	every := flags.Duration("every", time.Minute, "how often to check for reminders")
+	outFile := flags.String("out", "", "file to append notifications to")
+	once := flags.Bool("once", false, "check for reminders once")
+	asJSON := flags.Bool("json", false, "print JSON")
+
+	switch command {
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+		return nil
+	case "add", "done", "reopen", "rm", "ls", "agenda", "remind":
+	default:
+		return invalidInput(fmt.Errorf("unknown todo command %q, see 'notes help'", command))
+	}
+	positional, err := parseInterspersed(flags, args[1:])
+	if err != nil {
+		return invalidInput(err)
+	}
+	if command == "remind" {
+		out := io.Writer(os.Stdout)
+		if *outFile != "" {
+			file, err := os.OpenFile(*outFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+			if err != nil {
+				return errors.New("failed to open " + *outFile)
+			}
+			defer file.Close()
+			out = file
+		}
+		return remindTodos(todo.SystemClock, out, *every, *once, *asJSON)
+	}
+	list, err := todo.Load(todo.ListFile)
+	if err != nil {
+		return err
+	}
+
+	var changed todo.Todo
+	switch command {
+	case "ls":
+		filter, err := todoFilter(positional)
+		if err != nil {
+			return invalidInput(err)
+		}
+		return writeOutput(todoList(list.Find(filter)), *asJSON)
+	case "agenda":
+		return writeOutput(list.Agenda(todo.SystemClock), *asJSON)
+	case "add":
+		due, atTime, err := todo.ParseDue(*dueText)
+		if err != nil {
+			return invalidInput(err)
+		}
+		remind, err := todo.ParseRemind(*remindText, due)
+		if err != nil {
+			return invalidInput(err)
+		}
+		changed, err = list.Add(strings.Join(positional, " "), *priority, due, atTime, remind)
+		if err != nil {
+			return invalidInput(err)
+		}
+	default:
+		changed, err = changeTodo(list, command, positional)
+		if err != nil {
+			return err
+		}
+	}
+	err = list.Save()
+	if err != nil {
+		return err
+	}
+	return writeOutput(changed, *asJSON)
+}
+
+func findCommand(query string, asJSON bool) error {
+	store, err := note.Open(note.Dir)
+	if err != nil {
+		return err
+	}
+	index, err := openIndex(store)
+	if err != nil {
+		return err
+	}
+	results, err := index.Search(query, time.Now())
+	if err != nil {
+		return invalidInput(err)
+	}
+	return writeOutput(resultList(results), asJSON)
+}
+
+// parseInterspersed allows flags after positional arguments, as in
+// "notes new Ideas --tags work".
+func parseInterspersed(flags *flag.FlagSet, args []string) ([]string, error) {
+	positional := []string{}
+	for {
+		err := flags.Parse(args)
+		if err != nil {
+			return nil, err
+		}
+		if flags.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
+
+func isSet(flags *flag.FlagSet, name string) bool {
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		set = set || f.Name == name
+	})
+	return set
+}
+
+// saveOutput saves the data and prints it as it was saved.
+func saveOutput(data outputable, asJSON bool) error {
+	err := data.Save()
+	if err != nil {
+		return err
+	}
+	return writeOutput(data, asJSON)
+}
+
+func writeOutput(data displayer, asJSON bool) error {
+	if !asJSON {
+		data.Display()
+		return nil
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const (
+	endOfContent  = "."
+	editorCommand = ":edit"
+)
+
+// getContent reads note content over several lines until a line with only
+// a dot or the end of input. Typing :edit first opens $EDITOR on the
+// current content instead.
+func getContent(prompt, current string) string {
+	fmt.Printf("%s (end with a line with only %q, or %s to open $EDITOR):\n", prompt, endOfContent, editorCommand)
+	lines := []string{}
+	for {
+		line, ok := readLine()
+		if !ok || line == endOfContent {
+			break
+		}
+		if len(lines) == 0 && strings.TrimSpace(line) == editorCommand {
+			content, err := editContent(current)
+			if err != nil {
+				fmt.Println(err)
+			}
+			return content
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// editContent opens $VISUAL or $EDITOR, falling back to vi, on a temporary
+// file holding the content and returns what was saved.
+func editContent(content string) (string, error) {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	file, err := os.CreateTemp("", "note-*.md")
+	if err != nil {
+		return "", errors.New("failed to create temporary file")
+	}
+	defer os.Remove(file.Name())
+	_, err = file.WriteString(content)
+	closeErr := file.Close()
+	if err != nil || closeErr != nil {
+		return "", errors.New("failed to write temporary file")
+	}
+	args := append(strings.Fields(editor), file.Name())
+	command := exec.Command(args[0], args[1:]...)
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	err = command.Run()
+	if err != nil {
+		return "", fmt.Errorf("editor %s failed: %w", args[0], err)
+	}
+	data, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", errors.New("failed to read edited note")
+	}
+	return strings.TrimSpace(string(data)), nil
+}
@@ -0,0 +1,182 @@
+package export
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"example.com/notes/note"
+)
+
+// archiveDir is the folder the Markdown files are put in inside an archive.
+const archiveDir = "notes"
+
+var ErrUnknownFormat = errors.New("archive must end in .zip, .tar, .tar.gz or .tgz")
+
+// IsArchive reports whether the file name has an archive extension.
+func IsArchive(fileName string) bool {
+	return archiveFormat(fileName) != ""
+}
+
+// WriteArchive writes every note as Markdown into a single zip or tar file,
+// chosen by its extension. Tar files ending in .gz or .tgz are compressed.
+func WriteArchive(fileName string, notes []note.Note) error {
+	format := archiveFormat(fileName)
+	if format == "" {
+		return ErrUnknownFormat
+	}
+	file, err := os.Create(fileName)
+	if err != nil {
+		return errors.New("failed to create " + fileName)
+	}
+	if format == "zip" {
+		err = writeZip(file, notes)
+	} else {
+		err = writeTar(file, notes, format == "tgz")
+	}
+	closeErr := file.Close()
+	if err == nil && closeErr != nil {
+		err = errors.New("failed to write " + fileName)
+	}
+	if err != nil {
+		os.Remove(fileName)
+	}
+	return err
+}
+
+func writeZip(file io.Writer, notes []note.Note) error {
+	archive := zip.NewWriter(file)
+	for _, item := range notes {
+		header := &zip.FileHeader{Name: path.Join(archiveDir, item.ID+".md"), Method: zip.Deflate, Modified: item.UpdatedAt}
+		writer, err := archive.CreateHeader(header)
+		if err == nil {
+			_, err = writer.Write(Markdown(item))
+		}
+		if err != nil {
+			return errors.New("failed to write zip archive")
+		}
+	}
+	if archive.Close() != nil {
+		return errors.New("failed to write zip archive")
+	}
+	return nil
+}
+
+func writeTar(file io.Writer, notes []note.Note, compress bool) error {
+	var compressed *gzip.Writer
+	if compress {
+		compressed = gzip.NewWriter(file)
+		file = compressed
+	}
+	archive := tar.NewWriter(file)
+	for _, item := range notes {
+		data := Markdown(item)
+		header := &tar.Header{
+			Name:    path.Join(archiveDir, item.ID+".md"),
+			Mode:    0644,
+			Size:    int64(len(data)),
+			ModTime: item.UpdatedAt,
+		}
+		err := archive.WriteHeader(header)
+		if err == nil {
+			_, err = archive.Write(data)
+		}
+		if err != nil {
+			return errors.New("failed to write tar archive")
+		}
+	}
+	if archive.Close() != nil || compressed != nil && compressed.Close() != nil {
+		return errors.New("failed to write tar archive")
+	}
+	return nil
+}
+
+func readArchive(fileName string) ([]note.Note, error) {
+	format := archiveFormat(fileName)
+	if format == "zip" {
+		return readZip(fileName)
+	}
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, errors.New(fileName + " does not exist")
+	}
+	defer file.Close()
+	var reader io.Reader = file
+	if format == "tgz" {
+		compressed, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, errors.New("failed to read " + fileName)
+		}
+		reader = compressed
+	}
+	archive := tar.NewReader(reader)
+	notes := []note.Note{}
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return notes, nil
+		}
+		if err != nil {
+			return nil, errors.New("failed to read " + fileName)
+		}
+		if header.Typeflag != tar.TypeReg || !isMarkdown(header.Name) {
+			continue
+		}
+		data, err := io.ReadAll(archive)
+		if err != nil {
+			return nil, errors.New("failed to read " + header.Name)
+		}
+		item, err := Parse(data, baseName(header.Name))
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, item)
+	}
+}
+
+func readZip(fileName string) ([]note.Note, error) {
+	archive, err := zip.OpenReader(fileName)
+	if err != nil {
+		return nil, errors.New("failed to read " + fileName)
+	}
+	defer archive.Close()
+	notes := []note.Note{}
+	for _, entry := range archive.File {
+		if entry.FileInfo().IsDir() || !isMarkdown(entry.Name) {
+			continue
+		}
+		file, err := entry.Open()
+		if err != nil {
+			return nil, errors.New("failed to read " + entry.Name)
+		}
+		data, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			return nil, errors.New("failed to read " + entry.Name)
+		}
+		item, err := Parse(data, baseName(entry.Name))
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, item)
+	}
+	return notes, nil
+}
+
+func archiveFormat(fileName string) string {
+	name := strings.ToLower(fileName)
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return "zip"
+	case strings.HasSuffix(name, ".tar"):
+		return "tar"
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return "tgz"
+	}
+	return ""
+}
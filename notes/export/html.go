@@ -0,0 +1,256 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"example.com/notes/note"
+)
+
+var (
+	headingPattern = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*$`)
+	bulletPattern  = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	numberPattern  = regexp.MustCompile(`^\s*\d+[.)]\s+(.*)$`)
+	quotePattern   = regexp.MustCompile(`^&gt;\s?(.*)$`)
+	strongPattern  = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	codePattern    = regexp.MustCompile("`([^`]+)`")
+	wikiPattern    = regexp.MustCompile(`\[\[([^\[\]|]+)(?:\|([^\[\]]*))?\]\]`)
+	linkPattern    = regexp.MustCompile(`\[([^\[\]]+)\]\(([^()\s]+)\)`)
+)
+
+const style = `body { font-family: sans-serif; max-width: 46em; margin: 2em auto; padding: 0 1em; line-height: 1.5; color: #222; }
+nav { margin-bottom: 2em; }
+pre { background: #f4f4f4; padding: 1em; overflow-x: auto; }
+code { background: #f4f4f4; padding: 0 .2em; }
+blockquote { border-left: 3px solid #ccc; margin-left: 0; padding-left: 1em; color: #555; }
+.meta { color: #777; font-size: .9em; }
+.missing { color: #b00; }
+`
+
+var pages = template.Must(template.New("page").Funcs(template.FuncMap{"tagPage": tagPage}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<link rel="stylesheet" href="{{.Root}}style.css">
+</head>
+<body>
+<nav><a href="{{.Root}}index.html">All notes</a></nav>
+<h1>{{.Title}}</h1>
+{{with .Note}}<p class="meta">{{if .Notebook}}{{.Notebook}} · {{end}}{{.UpdatedAt.Format "2006-01-02 15:04"}}{{range .Tags}} <a href="{{$.Root}}{{tagPage .}}">#{{.}}</a>{{end}}</p>
+{{end}}{{.Body}}
+{{if .Notes}}<ul>
+{{range .Notes}}<li><a href="{{$.Root}}notes/{{.ID}}.html">{{.Title}}</a> <span class="meta">{{.UpdatedAt.Format "2006-01-02"}}</span></li>
+{{end}}</ul>
+{{end}}{{if .Tags}}<h2>Tags</h2>
+<p>{{range .Tags}}<a href="{{$.Root}}{{tagPage .}}">#{{.}}</a> {{end}}</p>
+{{end}}</body>
+</html>
+`))
+
+type page struct {
+	Title string
+	Root  string
+	Note  *note.Note
+	Body  template.HTML
+	Notes []note.Note
+	Tags  []string
+}
+
+// WriteSite writes a static HTML site to dir: an index of all notes, a
+// page per note with its backlinks in notes/ and a page per tag in tags/.
+// Keeping the note pages apart means no note ID can clash with index.html.
+func WriteSite(dir string, notes []note.Note) error {
+	for _, sub := range []string{"notes", "tags"} {
+		err := os.MkdirAll(filepath.Join(dir, sub), 0755)
+		if err != nil {
+			return errors.New("failed to create site directory")
+		}
+	}
+	tags := slices.Sorted(maps.Keys(note.Count(notes, false)))
+	err := writePage(filepath.Join(dir, "index.html"), page{Title: "Notes", Notes: notes, Tags: tags})
+	if err != nil {
+		return err
+	}
+	for _, item := range notes {
+		body := RenderHTML(item.Content, notes)
+		backlinks := note.Backlinks(notes, item)
+		if len(backlinks) > 0 {
+			body += "\n<h2>Linked from</h2>"
+		}
+		err = writePage(filepath.Join(dir, "notes", item.ID+".html"), page{
+			Title: item.Title,
+			Root:  "../",
+			Note:  &item,
+			Body:  template.HTML(body),
+			Notes: backlinks,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for _, tag := range tags {
+		err = writePage(filepath.Join(dir, tagPage(tag)), page{Title: "#" + tag, Root: "../", Notes: note.Tagged(notes, tag)})
+		if err != nil {
+			return err
+		}
+	}
+	err = os.WriteFile(filepath.Join(dir, "style.css"), []byte(style), 0644)
+	if err != nil {
+		return errors.New("failed to write style.css")
+	}
+	return nil
+}
+
+func writePage(fileName string, data page) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return errors.New("failed to create " + fileName)
+	}
+	err = pages.Execute(file, data)
+	closeErr := file.Close()
+	if err != nil || closeErr != nil {
+		return errors.New("failed to write " + fileName)
+	}
+	return nil
+}
+
+// tagPage names the page of a tag. Characters that are not safe in file
+// names are written as _xx.
+func tagPage(tag string) string {
+	var name strings.Builder
+	for _, b := range []byte(tag) {
+		if b >= 'a' && b <= 'z' || b >= '0' && b <= '9' || b == '-' {
+			name.WriteByte(b)
+		} else {
+			fmt.Fprintf(&name, "_%02x", b)
+		}
+	}
+	return "tags/" + name.String() + ".html"
+}
+
+// RenderHTML converts the Markdown of a note to HTML. Wiki links to other
+// notes point to their pages next to the note's own; links to missing notes
+// are marked.
+func RenderHTML(content string, notes []note.Note) string {
+	var out strings.Builder
+	var paragraph []string
+	list := ""
+	closeBlocks := func() {
+		if len(paragraph) > 0 {
+			out.WriteString("<p>" + strings.Join(paragraph, "\n") + "</p>\n")
+			paragraph = nil
+		}
+		if list != "" {
+			out.WriteString("</" + list + ">\n")
+			list = ""
+		}
+	}
+	openList := func(kind string) {
+		if list != kind {
+			closeBlocks()
+			out.WriteString("<" + kind + ">\n")
+			list = kind
+		}
+	}
+	fenced := false
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			if !fenced {
+				closeBlocks()
+				out.WriteString("<pre><code>")
+			} else {
+				out.WriteString("</code></pre>\n")
+			}
+			fenced = !fenced
+			continue
+		}
+		if fenced {
+			out.WriteString(html.EscapeString(line) + "\n")
+			continue
+		}
+		line = html.EscapeString(line)
+		if strings.TrimSpace(line) == "" {
+			closeBlocks()
+			continue
+		}
+		if match := headingPattern.FindStringSubmatch(line); match != nil {
+			closeBlocks()
+			fmt.Fprintf(&out, "<h%d>%s</h%d>\n", len(match[1]), inline(match[2], notes), len(match[1]))
+			continue
+		}
+		if match := bulletPattern.FindStringSubmatch(line); match != nil {
+			openList("ul")
+			out.WriteString("<li>" + inline(match[1], notes) + "</li>\n")
+			continue
+		}
+		if match := numberPattern.FindStringSubmatch(line); match != nil {
+			openList("ol")
+			out.WriteString("<li>" + inline(match[1], notes) + "</li>\n")
+			continue
+		}
+		if match := quotePattern.FindStringSubmatch(line); match != nil {
+			closeBlocks()
+			out.WriteString("<blockquote>" + inline(match[1], notes) + "</blockquote>\n")
+			continue
+		}
+		if list != "" {
+			closeBlocks()
+		}
+		paragraph = append(paragraph, inline(line, notes))
+	}
+	if fenced {
+		out.WriteString("</code></pre>\n")
+	}
+	closeBlocks()
+	return out.String()
+}
+
+// inline formats spans of an escaped line.
+func inline(text string, notes []note.Note) string {
+	text = codePattern.ReplaceAllString(text, "<code>$1</code>")
+	text = strongPattern.ReplaceAllString(text, "<strong>$1$2</strong>")
+	text = wikiPattern.ReplaceAllStringFunc(text, func(link string) string {
+		match := wikiPattern.FindStringSubmatch(link)
+		target, label := strings.TrimSpace(html.UnescapeString(match[1])), match[2]
+		if label == "" {
+			label = match[1]
+		}
+		found, ok := note.Resolve(notes, target)
+		if !ok {
+			return `<span class="missing">` + label + `</span>`
+		}
+		return `<a href="` + found.ID + `.html">` + label + `</a>`
+	})
+	return linkPattern.ReplaceAllStringFunc(text, func(link string) string {
+		match := linkPattern.FindStringSubmatch(link)
+		if !allowedLink(match[2]) {
+			return match[1]
+		}
+		return `<a href="` + match[2] + `">` + match[1] + `</a>`
+	})
+}
+
+// allowedLink reports whether an escaped link target may become an href:
+// only http, https and mailto links and relative ones are, so javascript:,
+// data: and the like are exported as plain text.
+func allowedLink(target string) bool {
+	link, err := url.Parse(html.UnescapeString(target))
+	if err != nil {
+		return false
+	}
+	switch link.Scheme {
+	case "", "http", "https", "mailto":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,225 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"example.com/notes/note"
+)
+
+const frontMatter = "---"
+
+// Markdown writes the note as Markdown with YAML front matter holding its
+// ID, title, notebook, tags and timestamps.
+func Markdown(item note.Note) []byte {
+	var out bytes.Buffer
+	fmt.Fprintln(&out, frontMatter)
+	fmt.Fprintf(&out, "id: %s\n", item.ID)
+	fmt.Fprintf(&out, "title: %s\n", strconv.Quote(item.Title))
+	if item.Notebook != "" {
+		fmt.Fprintf(&out, "notebook: %s\n", strconv.Quote(item.Notebook))
+	}
+	if len(item.Tags) > 0 {
+		tags, _ := json.Marshal(item.Tags)
+		fmt.Fprintf(&out, "tags: %s\n", tags)
+	}
+	fmt.Fprintf(&out, "created: %s\n", item.CreatedAt.Format(time.RFC3339))
+	fmt.Fprintf(&out, "updated: %s\n", item.UpdatedAt.Format(time.RFC3339))
+	fmt.Fprintln(&out, frontMatter)
+	fmt.Fprintln(&out)
+	out.WriteString(item.Content)
+	out.WriteString("\n")
+	return out.Bytes()
+}
+
+// Parse reads a Markdown note. Without front matter, or without a title in
+// it, the first heading and then name are used as the title. Missing
+// timestamps are set to now.
+func Parse(data []byte, name string) (note.Note, error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	item := note.Note{}
+	if rest, ok := strings.CutPrefix(text, frontMatter+"\n"); ok {
+		header, content, found := strings.Cut(rest, "\n"+frontMatter+"\n")
+		if !found {
+			header, found = strings.CutSuffix(rest, "\n"+frontMatter)
+		}
+		if !found {
+			return note.Note{}, errors.New("front matter of " + name + " is not closed")
+		}
+		err := parseHeader(header, &item)
+		if err != nil {
+			return note.Note{}, fmt.Errorf("%s: %w", name, err)
+		}
+		text = content
+	}
+	item.Content = strings.TrimSpace(text)
+	if item.Title == "" {
+		item.Title = firstHeading(item.Content)
+	}
+	if item.Title == "" {
+		item.Title = name
+	}
+	if item.Content == "" {
+		return note.Note{}, errors.New(name + " has no content")
+	}
+	now := time.Now()
+	if item.CreatedAt.IsZero() {
+		item.CreatedAt = now
+	}
+	if item.UpdatedAt.IsZero() {
+		item.UpdatedAt = item.CreatedAt
+	}
+	return item, nil
+}
+
+// parseHeader reads the subset of YAML front matter that notes use: plain
+// and quoted strings, and tags as a [flow] or block list. Other keys are
+// ignored.
+func parseHeader(header string, item *note.Note) error {
+	var listKey string
+	for _, line := range strings.Split(header, "\n") {
+		if entry, ok := strings.CutPrefix(strings.TrimSpace(line), "- "); ok && listKey == "tags" {
+			item.Tags = append(item.Tags, unquote(entry))
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+		listKey = key
+		var err error
+		switch key {
+		case "id":
+			item.ID = unquote(value)
+		case "title":
+			item.Title = unquote(value)
+		case "notebook":
+			item.Notebook = unquote(value)
+		case "tags":
+			item.Tags = parseList(value)
+		case "created", "date":
+			item.CreatedAt, err = parseTime(unquote(value))
+		case "updated":
+			item.UpdatedAt, err = parseTime(unquote(value))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	item.Tags = note.ParseTags(strings.Join(item.Tags, ","))
+	return nil
+}
+
+func parseList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	var list []string
+	if json.Unmarshal([]byte(value), &list) == nil {
+		return list
+	}
+	for _, entry := range strings.Split(strings.Trim(value, "[]"), ",") {
+		list = append(list, unquote(strings.TrimSpace(entry)))
+	}
+	return list
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && value[0] == '"' {
+		if text, err := strconv.Unquote(value); err == nil {
+			return text
+		}
+	}
+	if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		return strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+	}
+	return value
+}
+
+func parseTime(value string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04", "2006-01-02"} {
+		moment, err := time.ParseInLocation(layout, value, time.Local)
+		if err == nil {
+			return moment, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+func firstHeading(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		if title, ok := strings.CutPrefix(line, "# "); ok {
+			return strings.TrimSpace(title)
+		}
+	}
+	return ""
+}
+
+// WriteMarkdown writes every note to <id>.md in dir.
+func WriteMarkdown(dir string, notes []note.Note) error {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return errors.New("failed to create export directory")
+	}
+	for _, item := range notes {
+		err = os.WriteFile(filepath.Join(dir, item.ID+".md"), Markdown(item), 0644)
+		if err != nil {
+			return errors.New("failed to write " + item.ID + ".md")
+		}
+	}
+	return nil
+}
+
+// Read parses a Markdown file, every Markdown file below a directory or
+// the Markdown files in a zip or tar archive.
+func Read(path string) ([]note.Note, error) {
+	if IsArchive(path) {
+		return readArchive(path)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, errors.New(path + " does not exist")
+	}
+	files := []string{path}
+	if info.IsDir() {
+		files = nil
+		filepath.WalkDir(path, func(fileName string, entry fs.DirEntry, err error) error {
+			if err == nil && !entry.IsDir() && isMarkdown(fileName) {
+				files = append(files, fileName)
+			}
+			return nil
+		})
+	}
+	notes := []note.Note{}
+	for _, fileName := range files {
+		data, err := os.ReadFile(fileName)
+		if err != nil {
+			return nil, errors.New("failed to read " + fileName)
+		}
+		item, err := Parse(data, baseName(fileName))
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, item)
+	}
+	return notes, nil
+}
+
+func isMarkdown(fileName string) bool {
+	extension := strings.ToLower(filepath.Ext(fileName))
+	return extension == ".md" || extension == ".markdown"
+}
+
+func baseName(fileName string) string {
+	name := filepath.Base(filepath.ToSlash(fileName))
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
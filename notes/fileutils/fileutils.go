@@ -0,0 +1,25 @@
+package fileutils
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// WriteAtomic replaces the file through a temporary file, so a crash never
+// leaves half a file behind.
+func WriteAtomic(fileName string, data []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(fileName), filepath.Base(fileName)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	_, err = file.Write(data)
+	closeErr := file.Close()
+	if err != nil {
+		return err
+	}
+	if closeErr != nil {
+		return closeErr
+	}
+	return os.Rename(file.Name(), fileName)
+}
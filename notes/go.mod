@@ -1,3 +1,3 @@
 module example.com/notes
 
-go 1.23.1
+go 1.24
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"example.com/notes/note"
+)
+
+type revisionList []note.Revision
+
+func (revisions revisionList) Display() {
+	if len(revisions) == 0 {
+		fmt.Println("No revisions")
+	}
+	for _, revision := range revisions {
+		added, removed := revision.Changes()
+		fmt.Printf("#%-3d %s  %-32s +%d -%d", revision.Number, revision.Time.Format("2006-01-02 15:04"), revision.Title, added, removed)
+		if revision.Restored > 0 {
+			fmt.Printf("  restored from #%d", revision.Restored)
+		}
+		fmt.Println()
+	}
+}
+
+// revisionDiff compares revision From with revision To, or with the current
+// note when To is 0.
+type revisionDiff struct {
+	From int    `json:"from"`
+	To   int    `json:"to,omitempty"`
+	Diff string `json:"diff"`
+}
+
+func (diff revisionDiff) Display() {
+	to := "current"
+	if diff.To > 0 {
+		to = "#" + strconv.Itoa(diff.To)
+	}
+	fmt.Printf("--- #%d\n+++ %s\n", diff.From, to)
+	if diff.Diff == "" {
+		fmt.Println("No changes")
+	}
+	fmt.Print(diff.Diff)
+}
+
+// revisionArgs splits "ID 3 [5]" into the note reference and the revision
+// numbers. Up to max trailing numbers are revisions and the words before
+// them the reference, so it can be a title with spaces.
+func revisionArgs(args []string, max int) (string, []int, error) {
+	end := len(args)
+	for end > 1 && len(args)-end < max {
+		_, err := strconv.Atoi(strings.TrimPrefix(args[end-1], "#"))
+		if err != nil {
+			break
+		}
+		end--
+	}
+	if end == len(args) {
+		return "", nil, errors.New("expected a note ID or title and revision numbers")
+	}
+	numbers := []int{}
+	for _, arg := range args[end:] {
+		number, _ := strconv.Atoi(strings.TrimPrefix(arg, "#"))
+		numbers = append(numbers, number)
+	}
+	return strings.Join(args[:end], " "), numbers, nil
+}
+
+func diffRevisions(store *note.Store, found note.Note, numbers []int) (revisionDiff, error) {
+	if len(numbers) > 2 {
+		return revisionDiff{}, errors.New("usage: diff ID FROM [TO]")
+	}
+	from, err := store.Revision(found.ID, numbers[0])
+	if err != nil {
+		return revisionDiff{}, err
+	}
+	diff := revisionDiff{From: from.Number}
+	content := found.Content
+	if len(numbers) == 2 {
+		to, err := store.Revision(found.ID, numbers[1])
+		if err != nil {
+			return revisionDiff{}, err
+		}
+		diff.To, content = to.Number, to.Content
+	}
+	diff.Diff = note.Diff(from.Content, content)
+	return diff, nil
+}
+
+// revisionCount is how many revision numbers the command takes at most.
+func revisionCount(command string) int {
+	if command == "diff" {
+		return 2
+	}
+	return 1
+}
+
+// manageRevisions runs the interactive history, diff and restore commands.
+func manageRevisions(store *note.Store, command, reference string) error {
+	args := strings.Fields(reference)
+	if command == "history" {
+		found, err := store.Find(reference)
+		if err != nil {
+			return err
+		}
+		revisions, err := store.History(found.ID)
+		if err != nil {
+			return err
+		}
+		revisionList(revisions).Display()
+		return nil
+	}
+	reference, numbers, err := revisionArgs(args, revisionCount(command))
+	if err != nil {
+		return err
+	}
+	found, err := store.Find(reference)
+	if err != nil {
+		return err
+	}
+	if command == "diff" {
+		diff, err := diffRevisions(store, found, numbers)
+		if err != nil {
+			return err
+		}
+		diff.Display()
+		return nil
+	}
+	if len(numbers) != 1 {
+		return errors.New("usage: restore ID REVISION")
+	}
+	restored, err := store.Restore(found.ID, numbers[0])
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Restored %s from #%d\n", restored.ID, numbers[0])
+	return nil
+}
@@ -0,0 +1,310 @@
+package note
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"example.com/notes/fileutils"
+	"example.com/notes/search"
+)
+
+// keyFile marks an encrypted notes directory. It holds the salt the key is
+// derived with and a sealed check value to recognize a wrong passphrase.
+const keyFile = ".encryption.json"
+
+const (
+	kdf        = "pbkdf2-sha256"
+	iterations = 600_000
+	keyLength  = 32
+	checkValue = "notes"
+)
+
+var (
+	ErrNoPassphrase    = errors.New("notes are encrypted, a passphrase is needed")
+	ErrWrongPassphrase = errors.New("wrong passphrase")
+	ErrEncrypted       = errors.New("notes are already encrypted")
+	ErrNotEncrypted    = errors.New("notes are not encrypted")
+	ErrPartlyEncrypted = errors.New("notes are partly encrypted, run encrypt or decrypt again to finish")
+)
+
+// Passphrase is asked for the passphrase when an encrypted notes directory
+// is opened. By default it is read from NOTES_PASSPHRASE.
+var Passphrase = func() (string, error) {
+	return os.Getenv("NOTES_PASSPHRASE"), nil
+}
+
+// keys caches the cipher of each encrypted directory, so the passphrase is
+// asked for and the key derived only once.
+var keys = map[string]cipher.AEAD{}
+
+type keyParams struct {
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Check      []byte `json:"check"`
+}
+
+// sealed is an encrypted file. The nonce is random for every write and the
+// file's path inside the notes directory is authenticated with it, so
+// sealed files cannot be swapped.
+type sealed struct {
+	Nonce  []byte `json:"nonce"`
+	Sealed []byte `json:"sealed"`
+}
+
+// Encrypted reports whether the notes are encrypted at rest.
+func (store *Store) Encrypted() bool {
+	return store.aead != nil
+}
+
+// Encrypt seals all notes and their revisions with a key derived from the
+// passphrase. The key file is written first, so an interrupted run leaves
+// notes that can still be opened; running Encrypt again then seals the rest
+// with the key the store was opened with.
+func (store *Store) Encrypt(passphrase string) error {
+	aead := store.aead
+	if aead != nil && !store.partlyPlain() {
+		return ErrEncrypted
+	}
+	if aead == nil {
+		var err error
+		aead, err = store.newKey(passphrase)
+		if err != nil {
+			return err
+		}
+	}
+	err := store.reseal(aead)
+	if err != nil {
+		return err
+	}
+	// The search index holds the words of every note, so it is only kept in
+	// memory from now on.
+	os.Remove(search.Path(store.dir))
+	return nil
+}
+
+// newKey derives a key from the passphrase and writes the key file.
+func (store *Store) newKey(passphrase string) (cipher.AEAD, error) {
+	if passphrase == "" {
+		return nil, errors.New("passphrase cannot be empty")
+	}
+	params := keyParams{KDF: kdf, Iterations: iterations, Salt: make([]byte, 16)}
+	rand.Read(params.Salt)
+	aead, err := deriveKey(passphrase, params)
+	if err != nil {
+		return nil, err
+	}
+	params.Check, err = json.Marshal(seal(aead, keyFile, []byte(checkValue)))
+	if err != nil {
+		return nil, errors.New("failed to convert key to json")
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, errors.New("failed to convert key to json")
+	}
+	err = fileutils.WriteAtomic(filepath.Join(store.dir, keyFile), data)
+	if err != nil {
+		return nil, errors.New("failed to write key file")
+	}
+	keys[store.keyName()] = aead
+	return aead, nil
+}
+
+// Decrypt stores all notes and revisions as plain JSON again.
+func (store *Store) Decrypt() error {
+	if store.aead == nil {
+		return ErrNotEncrypted
+	}
+	err := store.reseal(nil)
+	if err != nil {
+		return err
+	}
+	delete(keys, store.keyName())
+	err = os.Remove(filepath.Join(store.dir, keyFile))
+	if err != nil {
+		return errors.New("failed to remove key file")
+	}
+	return nil
+}
+
+// reseal rewrites every note and revision file with the given cipher, or as
+// plain JSON when it is nil. Files already in that form are skipped, so an
+// interrupted run can be finished.
+func (store *Store) reseal(aead cipher.AEAD) error {
+	target := &Store{dir: store.dir, aead: aead}
+	for _, fileName := range store.files() {
+		data, plain, err := store.read(fileName)
+		if err != nil {
+			return err
+		}
+		if len(data) == 0 || plain == (aead == nil) {
+			continue
+		}
+		err = target.writeFile(fileName, data)
+		if err != nil {
+			return errors.New("failed to rewrite " + filepath.Base(fileName))
+		}
+	}
+	store.aead = aead
+	return nil
+}
+
+// partlyPlain reports whether an encrypted directory still holds plain
+// files, as an interrupted Encrypt or Decrypt leaves behind.
+func (store *Store) partlyPlain() bool {
+	for _, fileName := range store.files() {
+		data, plain, err := store.read(fileName)
+		if err == nil && plain && len(data) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// files returns the note and revision files.
+func (store *Store) files() []string {
+	notes, _ := filepath.Glob(filepath.Join(store.dir, "*.json"))
+	revisions, _ := filepath.Glob(filepath.Join(store.dir, historyDir, "*.json"))
+	files := []string{}
+	for _, fileName := range append(notes, revisions...) {
+		id := strings.TrimSuffix(filepath.Base(fileName), ".json")
+		if idPattern.MatchString(id) {
+			files = append(files, fileName)
+		}
+	}
+	return files
+}
+
+// loadCipher returns the cipher of an encrypted directory, or nil when the
+// notes are kept as plain JSON.
+func loadCipher(dir string) (cipher.AEAD, error) {
+	data, err := os.ReadFile(filepath.Join(dir, keyFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.New("failed to read key file")
+	}
+	name, _ := filepath.Abs(dir)
+	if aead, ok := keys[name]; ok {
+		return aead, nil
+	}
+	var params keyParams
+	var check sealed
+	err = json.Unmarshal(data, &params)
+	if err == nil {
+		err = json.Unmarshal(params.Check, &check)
+	}
+	if err != nil || params.KDF != kdf {
+		return nil, errors.New("failed to parse key file")
+	}
+	passphrase, err := Passphrase()
+	if err != nil {
+		return nil, err
+	}
+	if passphrase == "" {
+		return nil, ErrNoPassphrase
+	}
+	aead, err := deriveKey(passphrase, params)
+	if err != nil {
+		return nil, err
+	}
+	value, err := aead.Open(nil, check.Nonce, check.Sealed, []byte(keyFile))
+	if err != nil || string(value) != checkValue {
+		return nil, ErrWrongPassphrase
+	}
+	keys[name] = aead
+	return aead, nil
+}
+
+func deriveKey(passphrase string, params keyParams) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, params.Salt, params.Iterations, keyLength)
+	if err != nil {
+		return nil, errors.New("failed to derive key")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.New("failed to derive key")
+	}
+	return cipher.NewGCM(block)
+}
+
+func seal(aead cipher.AEAD, name string, data []byte) sealed {
+	nonce := make([]byte, aead.NonceSize())
+	rand.Read(nonce)
+	return sealed{Nonce: nonce, Sealed: aead.Seal(nil, nonce, data, []byte(name))}
+}
+
+// readFile reads a note or revision file, opening it when it is sealed. In
+// an encrypted directory a plain file is refused: it was either left by an
+// interrupted Encrypt or Decrypt, or put there by someone without the key.
+func (store *Store) readFile(fileName string) ([]byte, error) {
+	data, plain, err := store.read(fileName)
+	if err != nil {
+		return nil, err
+	}
+	if plain && len(data) > 0 && store.aead != nil {
+		return nil, ErrPartlyEncrypted
+	}
+	return data, nil
+}
+
+// read reads a file, opening it when it is sealed, and reports whether it
+// was plain.
+func (store *Store) read(fileName string) ([]byte, bool, error) {
+	data, err := os.ReadFile(fileName)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, err
+	}
+	if err != nil {
+		return nil, false, errors.New("failed to read " + filepath.Base(fileName))
+	}
+	var file sealed
+	if json.Unmarshal(data, &file) != nil || file.Sealed == nil {
+		return data, true, nil
+	}
+	if store.aead == nil {
+		return nil, false, ErrNoPassphrase
+	}
+	data, err = store.aead.Open(nil, file.Nonce, file.Sealed, []byte(store.fileKey(fileName)))
+	if err != nil {
+		return nil, false, errors.New("failed to decrypt " + filepath.Base(fileName))
+	}
+	return data, false, nil
+}
+
+// writeFile writes a note or revision file, sealed when the notes are
+// encrypted.
+func (store *Store) writeFile(fileName string, data []byte) error {
+	if store.aead != nil {
+		var err error
+		data, err = json.Marshal(seal(store.aead, store.fileKey(fileName), data))
+		if err != nil {
+			return err
+		}
+	}
+	return fileutils.WriteAtomic(fileName, data)
+}
+
+// fileKey is the path of a file inside the notes directory, which is
+// authenticated along with its content.
+func (store *Store) fileKey(fileName string) string {
+	name, err := filepath.Rel(store.dir, fileName)
+	if err != nil {
+		return fileName
+	}
+	return filepath.ToSlash(name)
+}
+
+func (store *Store) keyName() string {
+	name, _ := filepath.Abs(store.dir)
+	return name
+}
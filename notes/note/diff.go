@@ -0,0 +1,146 @@
+package note
+
+import "strings"
+
+// diffContext is the number of unchanged lines shown around a change.
+const diffContext = 2
+
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// Diff compares two versions line by line. Removed lines start with "- ",
+// added ones with "+ " and unchanged context lines with two spaces; "..."
+// stands for unchanged lines left out.
+func Diff(old, new string) string {
+	lines := diffLines(splitLines(old), splitLines(new))
+	var out strings.Builder
+	skipped := false
+	for i, line := range lines {
+		if line.op == ' ' && !nearChange(lines, i) {
+			skipped = true
+			continue
+		}
+		if skipped && out.Len() > 0 {
+			out.WriteString("...\n")
+		}
+		skipped = false
+		out.WriteString(string(line.op) + " " + line.text + "\n")
+	}
+	return out.String()
+}
+
+// diffStat counts the added and removed lines of a diff.
+func diffStat(diff string) (int, int) {
+	added, removed := 0, 0
+	for _, line := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(line, "+ ") {
+			added++
+		} else if strings.HasPrefix(line, "- ") {
+			removed++
+		}
+	}
+	return added, removed
+}
+
+func nearChange(lines []diffLine, i int) bool {
+	for j := max(0, i-diffContext); j <= min(len(lines)-1, i+diffContext); j++ {
+		if lines[j].op != ' ' {
+			return true
+		}
+	}
+	return false
+}
+
+// diffLines aligns the lines along their longest common subsequence. The
+// common start and end are matched up front and the rest is split in halves
+// (Hirschberg's algorithm), so memory grows with the lines rather than with
+// their product.
+func diffLines(a, b []string) []diffLine {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	lines := []diffLine{}
+	for _, line := range a[:prefix] {
+		lines = append(lines, diffLine{' ', line})
+	}
+	lines = alignLines(lines, a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])
+	for _, line := range a[len(a)-suffix:] {
+		lines = append(lines, diffLine{' ', line})
+	}
+	return lines
+}
+
+func alignLines(lines []diffLine, a, b []string) []diffLine {
+	switch {
+	case len(a) == 0:
+		for _, line := range b {
+			lines = append(lines, diffLine{'+', line})
+		}
+		return lines
+	case len(b) == 0:
+		for _, line := range a {
+			lines = append(lines, diffLine{'-', line})
+		}
+		return lines
+	case len(a) == 1:
+		for j, line := range b {
+			if line == a[0] {
+				lines = alignLines(lines, nil, b[:j])
+				lines = append(lines, diffLine{' ', line})
+				return alignLines(lines, nil, b[j+1:])
+			}
+		}
+		lines = append(lines, diffLine{'-', a[0]})
+		return alignLines(lines, nil, b)
+	}
+	mid := len(a) / 2
+	forward := commonLengths(a[:mid], b, false)
+	backward := commonLengths(a[mid:], b, true)
+	split := 0
+	for j := range forward {
+		if forward[j]+backward[len(b)-j] > forward[split]+backward[len(b)-split] {
+			split = j
+		}
+	}
+	lines = alignLines(lines, a[:mid], b[:split])
+	return alignLines(lines, a[mid:], b[split:])
+}
+
+// commonLengths returns, for every j, the length of the longest common
+// subsequence of a and the first j lines of b, or the last j lines when
+// reversed is set. It keeps only two rows of the table.
+func commonLengths(a, b []string, reversed bool) []int {
+	at := func(lines []string, i int) string {
+		if reversed {
+			return lines[len(lines)-1-i]
+		}
+		return lines[i]
+	}
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for i := range a {
+		for j := range b {
+			if at(a, i) == at(b, j) {
+				current[j+1] = previous[j] + 1
+			} else {
+				current[j+1] = max(previous[j+1], current[j])
+			}
+		}
+		previous, current = current, previous
+	}
+	return previous
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+}
@@ -0,0 +1,46 @@
+package note
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     string
+	}{
+		{"unchanged", "a\nb", "a\nb", ""},
+		{"added", "", "a", "+ a\n"},
+		{"removed", "a", "", "- a\n"},
+		{"changed line", "a\nb\nc", "a\nx\nc", "  a\n- b\n+ x\n  c\n"},
+		{"moved line", "a\nb\nc\nd", "b\nc\nd\na", "- a\n  b\n  c\n  d\n+ a\n"},
+		{"context", "1\n2\n3\n4\n5\n6\n7\n8", "1\n2\n3\n4\nx\n5\n6\n7\n8", "  3\n  4\n+ x\n  5\n  6\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Diff(test.old, test.new); got != test.want {
+				t.Errorf("Diff() =\n%s\nwant\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDiffLargeNote(t *testing.T) {
+	old := []string{}
+	new := []string{}
+	for i := range 5000 {
+		line := strings.Repeat("x", i%50)
+		old = append(old, line)
+		if i%500 == 0 {
+			new = append(new, "changed")
+			continue
+		}
+		new = append(new, line)
+	}
+	added, removed := diffStat(Diff(strings.Join(old, "\n"), strings.Join(new, "\n")))
+	if added != 10 || removed != 10 {
+		t.Errorf("diffStat() = %d, %d, want 10, 10", added, removed)
+	}
+}
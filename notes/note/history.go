@@ -0,0 +1,194 @@
+package note
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// historyDir holds the revisions of each note as revisions/<id>.json inside
+// the notes directory.
+const historyDir = "revisions"
+
+var ErrNoRevision = errors.New("revision does not exist")
+
+// Revision is a note as it was saved once, with the diff of its content
+// against the revision before.
+type Revision struct {
+	Number   int       `json:"number"`
+	Time     time.Time `json:"time"`
+	Title    string    `json:"title"`
+	Content  string    `json:"content"`
+	Tags     []string  `json:"tags,omitempty"`
+	Notebook string    `json:"notebook,omitempty"`
+	Restored int       `json:"restored,omitempty"`
+	Diff     string    `json:"diff"`
+}
+
+// Changes returns the number of added and removed content lines.
+func (revision Revision) Changes() (int, int) {
+	return diffStat(revision.Diff)
+}
+
+// Retention limits the revisions kept of each note: at most Keep of them
+// and none older than MaxAge. Zero means no limit. The latest revision is
+// always kept.
+type Retention struct {
+	Keep   int
+	MaxAge time.Duration
+}
+
+var DefaultRetention = Retention{Keep: 50}
+
+// History returns the revisions of a note, oldest first.
+func (store *Store) History(id string) ([]Revision, error) {
+	if !store.exists(id) {
+		return nil, ErrNotFound
+	}
+	return store.readHistory(id)
+}
+
+func (store *Store) Revision(id string, number int) (Revision, error) {
+	revisions, err := store.History(id)
+	if err != nil {
+		return Revision{}, err
+	}
+	index := slices.IndexFunc(revisions, func(revision Revision) bool {
+		return revision.Number == number
+	})
+	if index < 0 {
+		return Revision{}, ErrNoRevision
+	}
+	return revisions[index], nil
+}
+
+// Restore saves an old revision as the current version of the note. The
+// restore is a new revision, so it can be undone in turn.
+func (store *Store) Restore(id string, number int) (Note, error) {
+	revision, err := store.Revision(id, number)
+	if err != nil {
+		return Note{}, err
+	}
+	note, err := store.Get(id)
+	if err != nil {
+		return Note{}, err
+	}
+	note.Title = revision.Title
+	note.Content = revision.Content
+	note.Tags = revision.Tags
+	note.Notebook = revision.Notebook
+	note.restored = number
+	return note, store.Update(&note)
+}
+
+// Prune applies the retention policy to the revisions of all notes.
+func (store *Store) Prune(retention Retention) error {
+	files, err := filepath.Glob(filepath.Join(store.dir, historyDir, "*.json"))
+	if err != nil {
+		return nil
+	}
+	for _, fileName := range files {
+		id := strings.TrimSuffix(filepath.Base(fileName), ".json")
+		revisions, err := store.readHistory(id)
+		if err != nil {
+			return err
+		}
+		err = store.writeHistory(id, retention.apply(revisions, time.Now()))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (retention Retention) apply(revisions []Revision, now time.Time) []Revision {
+	first := 0
+	if retention.Keep > 0 && len(revisions) > retention.Keep {
+		first = len(revisions) - retention.Keep
+	}
+	for retention.MaxAge > 0 && first < len(revisions)-1 && now.Sub(revisions[first].Time) > retention.MaxAge {
+		first++
+	}
+	return revisions[first:]
+}
+
+// seedHistory keeps the saved version of a note that has no revisions yet,
+// as notes written before revisions were kept, so the next save has
+// something to diff against.
+func (store *Store) seedHistory(id string) error {
+	revisions, err := store.readHistory(id)
+	if err != nil || len(revisions) > 0 {
+		return err
+	}
+	previous, err := store.Get(id)
+	if err != nil {
+		return err
+	}
+	return store.record(previous)
+}
+
+// record adds the note as a new revision unless it is unchanged since the
+// last one.
+func (store *Store) record(note Note) error {
+	revisions, err := store.readHistory(note.ID)
+	if err != nil {
+		return err
+	}
+	last := Revision{}
+	if len(revisions) > 0 {
+		last = revisions[len(revisions)-1]
+		if last.Title == note.Title && last.Content == note.Content && last.Notebook == note.Notebook && slices.Equal(last.Tags, note.Tags) {
+			return nil
+		}
+	}
+	revisions = append(revisions, Revision{
+		Number:   last.Number + 1,
+		Time:     note.UpdatedAt,
+		Title:    note.Title,
+		Content:  note.Content,
+		Tags:     note.Tags,
+		Notebook: note.Notebook,
+		Restored: note.restored,
+		Diff:     Diff(last.Content, note.Content),
+	})
+	return store.writeHistory(note.ID, DefaultRetention.apply(revisions, time.Now()))
+}
+
+func (store *Store) readHistory(id string) ([]Revision, error) {
+	data, err := store.readFile(store.historyPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return []Revision{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	revisions := []Revision{}
+	err = json.Unmarshal(data, &revisions)
+	if err != nil {
+		return nil, errors.New("failed to parse history of note " + id)
+	}
+	return revisions, nil
+}
+
+func (store *Store) writeHistory(id string, revisions []Revision) error {
+	data, err := json.Marshal(revisions)
+	if err != nil {
+		return errors.New("failed to convert note history to json")
+	}
+	err = os.MkdirAll(filepath.Join(store.dir, historyDir), 0755)
+	if err == nil {
+		err = store.writeFile(store.historyPath(id), data)
+	}
+	if err != nil {
+		return errors.New("failed to write note history")
+	}
+	return nil
+}
+
+func (store *Store) historyPath(id string) string {
+	return filepath.Join(store.dir, historyDir, id+".json")
+}
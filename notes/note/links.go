@@ -0,0 +1,118 @@
+package note
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// linkPattern matches [[target]] and [[target|text]], where target is a
+// note ID or title.
+var linkPattern = regexp.MustCompile(`\[\[([^\[\]|]+)(\|[^\[\]]*)?\]\]`)
+
+// Links returns the targets of the [[wiki-style]] links in the content, each
+// once.
+func Links(content string) []string {
+	targets := []string{}
+	for _, match := range linkPattern.FindAllStringSubmatch(content, -1) {
+		target := strings.TrimSpace(match[1])
+		if target != "" && !slices.Contains(targets, target) {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// LinksTo reports whether one of the note's links resolves to target among
+// notes. A link to a title several notes share only reaches the oldest.
+func (note Note) LinksTo(notes []Note, target Note) bool {
+	for _, link := range Links(note.Content) {
+		if found, ok := Resolve(notes, link); ok && found.ID == target.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve finds the note a link points to: the note with that ID, or else
+// the oldest note with that title.
+func Resolve(notes []Note, link string) (Note, bool) {
+	for _, note := range notes {
+		if note.ID == link {
+			return note, true
+		}
+	}
+	for _, note := range notes {
+		if strings.EqualFold(note.Title, link) {
+			return note, true
+		}
+	}
+	return Note{}, false
+}
+
+// Backlinks returns the notes linking to target.
+func Backlinks(notes []Note, target Note) []Note {
+	found := []Note{}
+	for _, note := range notes {
+		if note.ID != target.ID && note.LinksTo(notes, target) {
+			found = append(found, note)
+		}
+	}
+	return found
+}
+
+func Tagged(notes []Note, tag string) []Note {
+	tag = normalizeTag(tag)
+	found := []Note{}
+	for _, note := range notes {
+		if slices.Contains(note.Tags, tag) {
+			found = append(found, note)
+		}
+	}
+	return found
+}
+
+func InNotebook(notes []Note, notebook string) []Note {
+	found := []Note{}
+	for _, note := range notes {
+		if strings.EqualFold(note.Notebook, strings.TrimSpace(notebook)) {
+			found = append(found, note)
+		}
+	}
+	return found
+}
+
+// Count returns how many notes carry each tag, or belong to each notebook
+// when notebooks is true.
+func Count(notes []Note, notebooks bool) map[string]int {
+	counts := map[string]int{}
+	for _, note := range notes {
+		if notebooks {
+			if note.Notebook != "" {
+				counts[note.Notebook]++
+			}
+			continue
+		}
+		for _, tag := range note.Tags {
+			counts[tag]++
+		}
+	}
+	return counts
+}
+
+// ParseTags reads a comma separated list of tags. Tags are lower case and
+// may start with #.
+func ParseTags(text string) []string {
+	tags := []string{}
+	for _, tag := range strings.Split(text, ",") {
+		tag = normalizeTag(tag)
+		if tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+}
@@ -0,0 +1,100 @@
+package note
+
+import (
+	"os"
+	"regexp"
+	"strings"
+)
+
+const (
+	bold      = "\033[1m"
+	underline = "\033[4m"
+	cyan      = "\033[36m"
+	reset     = "\033[0m"
+)
+
+var (
+	headingPattern = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*$`)
+	bulletPattern  = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	numberPattern  = regexp.MustCompile(`^(\s*)(\d+)[.)]\s+(.*)$`)
+	quotePattern   = regexp.MustCompile(`^>\s?(.*)$`)
+	strongPattern  = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	codePattern    = regexp.MustCompile("`([^`]+)`")
+)
+
+// Render formats Markdown for the terminal: headings, bullet and numbered
+// lists, quotes, code blocks and **bold** and `code` spans. Without styled
+// no escape codes are written, so the output can be piped to a file.
+func Render(content string, styled bool) string {
+	var out strings.Builder
+	fenced := false
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			fenced = !fenced
+			continue
+		}
+		if fenced {
+			out.WriteString("    " + style(line, cyan, styled) + "\n")
+			continue
+		}
+		if match := headingPattern.FindStringSubmatch(line); match != nil {
+			out.WriteString(heading(inline(match[2], styled), len(match[1]), styled) + "\n")
+			continue
+		}
+		if match := bulletPattern.FindStringSubmatch(line); match != nil {
+			out.WriteString(match[1] + "• " + inline(match[2], styled) + "\n")
+			continue
+		}
+		if match := numberPattern.FindStringSubmatch(line); match != nil {
+			out.WriteString(match[1] + match[2] + ". " + inline(match[3], styled) + "\n")
+			continue
+		}
+		if match := quotePattern.FindStringSubmatch(line); match != nil {
+			out.WriteString("│ " + inline(match[1], styled) + "\n")
+			continue
+		}
+		out.WriteString(inline(line, styled) + "\n")
+	}
+	return out.String()
+}
+
+// heading underlines top level headings with = and second level ones with -
+// when the output is plain text.
+func heading(text string, level int, styled bool) string {
+	if styled && level == 1 {
+		return bold + underline + text + reset
+	}
+	if styled {
+		return bold + text + reset
+	}
+	switch level {
+	case 1:
+		return text + "\n" + strings.Repeat("=", len([]rune(text)))
+	case 2:
+		return text + "\n" + strings.Repeat("-", len([]rune(text)))
+	}
+	return text
+}
+
+func inline(text string, styled bool) string {
+	text = codePattern.ReplaceAllStringFunc(text, func(span string) string {
+		return style(strings.Trim(span, "`"), cyan, styled)
+	})
+	return strongPattern.ReplaceAllStringFunc(text, func(span string) string {
+		return style(span[2:len(span)-2], bold, styled)
+	})
+}
+
+func style(text, code string, styled bool) string {
+	if !styled || text == "" {
+		return text
+	}
+	return code + text + reset
+}
+
+// IsTerminal reports whether the file is a terminal rather than a file or
+// pipe.
+func IsTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
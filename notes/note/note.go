@@ -7,12 +7,21 @@ import (
 	"os"
 	"strings"
 	"time"
+
+	"example.com/notes/search"
 )
 
 type Note struct {
+	ID        string    `json:"id"`
 	Title     string    `json:"title"`
 	Content   string    `json:"content"`
+	Tags      []string  `json:"tags,omitempty"`
+	Notebook  string    `json:"notebook,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// restored is the revision this version was restored from.
+	restored int
 }
 
 func New(title, content string) (Note, error) {
@@ -24,11 +33,30 @@ func New(title, content string) (Note, error) {
 		Title:     title,
 		Content:   content,
 		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
 	}, nil
 }
 
 func (note Note) Display() {
-	fmt.Printf("Your note: %s\n Content: %s \n created at: %s", note.Title, note.Content, note.CreatedAt)
+	fmt.Printf("Your note: %s\n created at: %s\n", note.Title, note.CreatedAt.Format("2006-01-02 15:04"))
+	if note.Notebook != "" {
+		fmt.Printf(" notebook: %s\n", note.Notebook)
+	}
+	if len(note.Tags) > 0 {
+		fmt.Printf(" tags: #%s\n", strings.Join(note.Tags, " #"))
+	}
+	fmt.Println()
+	fmt.Print(Render(note.Content, IsTerminal(os.Stdout)))
+}
+
+func (note Note) Document() search.Document {
+	return search.Document{
+		Kind:    "note",
+		ID:      note.ID,
+		Title:   note.Title,
+		Text:    note.Content + " " + note.Notebook + " " + strings.Join(note.Tags, " "),
+		Updated: note.UpdatedAt,
+	}
 }
 
 func ConvertToJson(note Note) []byte {
@@ -39,9 +67,15 @@ func ConvertToJson(note Note) []byte {
 	return json
 }
 
-func (note Note) Save() error {
-	fileName := strings.ReplaceAll(note.Title, " ", "_")
-	fileName = strings.ToLower(fileName) + ".json"
-	noteJson := ConvertToJson(note)
-	return os.WriteFile(fileName, noteJson, 0644)
+// Save stores the note in the notes directory. A note without an ID is
+// added as a new note and gets its ID.
+func (note *Note) Save() error {
+	store, err := Open(Dir)
+	if err != nil {
+		return err
+	}
+	if note.ID == "" {
+		return store.Create(note)
+	}
+	return store.Update(note)
 }
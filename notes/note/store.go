@@ -0,0 +1,317 @@
+package note
+
+import (
+	"crypto/cipher"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"example.com/notes/search"
+)
+
+// Dir is where the notes are kept, one <id>.json file per note.
+const Dir = "notes"
+
+const maxSlugLength = 48
+
+var (
+	ErrNotFound  = errors.New("note does not exist")
+	ErrAmbiguous = errors.New("more than one note has that title, use its id")
+)
+
+var idPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+var separators = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Store keeps notes in a directory. A note's ID is derived from its title
+// when it is created and never changes, so renaming a note keeps its file.
+// Notes with the same title get the IDs "ideas", "ideas-2", "ideas-3" and
+// so on.
+type Store struct {
+	dir  string
+	aead cipher.AEAD
+}
+
+// Open returns the store in dir, creating the directory on first use. Notes
+// that older versions saved as <title>.json next to it are moved in then.
+// Opening an encrypted directory asks for the passphrase.
+func Open(dir string) (*Store, error) {
+	store := &Store{dir: dir}
+	_, err := os.Stat(dir)
+	if err == nil {
+		store.aead, err = loadCipher(dir)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	}
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, errors.New("failed to create notes directory")
+	}
+	return store, store.importLegacy()
+}
+
+// Create gives the note an ID and saves it as a new note.
+func (store *Store) Create(note *Note) error {
+	base := slug(note.Title)
+	for n := 1; ; n++ {
+		id := base
+		if n > 1 {
+			id = base + "-" + strconv.Itoa(n)
+		}
+		reserved, err := store.reserve(id)
+		if err != nil {
+			return err
+		}
+		if reserved {
+			note.ID = id
+			err = store.add(*note)
+			if err != nil {
+				note.ID = ""
+			}
+			return err
+		}
+	}
+}
+
+// Import adds a note exported from another store under its own ID. When
+// the ID is taken by the same note it is only updated if the imported copy
+// is newer, and when it is taken by another note the imported one gets a
+// new ID. It reports whether the note was saved.
+func (store *Store) Import(note *Note) (bool, error) {
+	if !idPattern.MatchString(note.ID) {
+		return true, store.Create(note)
+	}
+	current, err := store.Get(note.ID)
+	if errors.Is(err, ErrNotFound) {
+		reserved, err := store.reserve(note.ID)
+		if err != nil || !reserved {
+			return false, err
+		}
+		return true, store.add(*note)
+	}
+	if err != nil {
+		return false, err
+	}
+	// Exported times are precise to the second.
+	if !current.CreatedAt.Truncate(time.Second).Equal(note.CreatedAt.Truncate(time.Second)) {
+		return true, store.Create(note)
+	}
+	if !note.UpdatedAt.Truncate(time.Second).After(current.UpdatedAt.Truncate(time.Second)) {
+		return false, nil
+	}
+	err = store.seedHistory(note.ID)
+	if err != nil {
+		return false, err
+	}
+	return true, store.write(*note)
+}
+
+// reserve creates an empty file for the ID and reports false when the ID
+// is taken. Creating the file exclusively reserves the ID, even against
+// another process saving a note with the same title.
+func (store *Store) reserve(id string) (bool, error) {
+	file, err := os.OpenFile(store.path(id), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if errors.Is(err, os.ErrExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.New("failed to create note file")
+	}
+	file.Close()
+	return true, nil
+}
+
+// add writes a note whose ID was just reserved. If the note cannot be
+// written the empty reserved file is removed again, so the ID is free.
+func (store *Store) add(note Note) error {
+	err := store.write(note)
+	if err != nil && store.reserved(note.ID) {
+		os.Remove(store.path(note.ID))
+	}
+	return err
+}
+
+// reserved reports whether the ID only holds an empty reserved file.
+func (store *Store) reserved(id string) bool {
+	info, err := os.Stat(store.path(id))
+	return err == nil && info.Size() == 0
+}
+
+// Update saves a changed note over its previous version.
+func (store *Store) Update(note *Note) error {
+	if !store.exists(note.ID) {
+		return ErrNotFound
+	}
+	err := store.seedHistory(note.ID)
+	if err != nil {
+		return err
+	}
+	note.UpdatedAt = time.Now()
+	return store.write(*note)
+}
+
+func (store *Store) Get(id string) (Note, error) {
+	if !idPattern.MatchString(id) {
+		return Note{}, ErrNotFound
+	}
+	data, err := store.readFile(store.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return Note{}, ErrNotFound
+	}
+	if err != nil {
+		return Note{}, err
+	}
+	var note Note
+	err = json.Unmarshal(data, &note)
+	if err != nil {
+		return Note{}, errors.New("failed to parse note " + id)
+	}
+	note.ID = id
+	return note, nil
+}
+
+// Find looks a note up by ID, or else by its title ignoring case.
+func (store *Store) Find(reference string) (Note, error) {
+	note, err := store.Get(reference)
+	if !errors.Is(err, ErrNotFound) {
+		return note, err
+	}
+	notes, err := store.List()
+	if err != nil {
+		return Note{}, err
+	}
+	found := []Note{}
+	for _, note := range notes {
+		if strings.EqualFold(note.Title, reference) {
+			found = append(found, note)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return Note{}, ErrNotFound
+	case 1:
+		return found[0], nil
+	}
+	return Note{}, ErrAmbiguous
+}
+
+// List returns all notes, oldest first. IDs that are reserved but not
+// written yet are skipped.
+func (store *Store) List() ([]Note, error) {
+	entries, err := os.ReadDir(store.dir)
+	if err != nil {
+		return nil, errors.New("failed to read notes directory")
+	}
+	notes := []Note{}
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() || !idPattern.MatchString(id) || store.reserved(id) {
+			continue
+		}
+		note, err := store.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, note)
+	}
+	slices.SortFunc(notes, func(a, b Note) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return notes, nil
+}
+
+func (store *Store) Delete(id string) error {
+	if !store.exists(id) {
+		return ErrNotFound
+	}
+	err := os.Remove(store.path(id))
+	if err != nil {
+		return errors.New("failed to delete note")
+	}
+	os.Remove(store.historyPath(id))
+	return search.Update(search.Path(store.dir), nil, []string{Note{ID: id}.Document().Key()})
+}
+
+func (store *Store) exists(id string) bool {
+	if !idPattern.MatchString(id) {
+		return false
+	}
+	_, err := os.Stat(store.path(id))
+	return err == nil
+}
+
+// write saves the note, keeps it as a revision and updates the search
+// index.
+func (store *Store) write(note Note) error {
+	err := store.writeFile(store.path(note.ID), ConvertToJson(note))
+	if err != nil {
+		return errors.New("failed to write note")
+	}
+	err = store.record(note)
+	if err != nil {
+		return err
+	}
+	return search.Update(search.Path(store.dir), []search.Document{note.Document()}, nil)
+}
+
+func (store *Store) path(id string) string {
+	return filepath.Join(store.dir, id+".json")
+}
+
+// importLegacy moves notes that older versions saved as <title>.json in the
+// parent directory into the store. Only files named after their note's
+// title are taken, and they are kept as <title>.json.bak.
+func (store *Store) importLegacy() error {
+	parent := filepath.Dir(filepath.Clean(store.dir))
+	files, err := filepath.Glob(filepath.Join(parent, "*.json"))
+	if err != nil {
+		return nil
+	}
+	for _, fileName := range files {
+		data, err := os.ReadFile(fileName)
+		if err != nil {
+			continue
+		}
+		var note Note
+		err = json.Unmarshal(data, &note)
+		if err != nil || note.Title == "" || note.CreatedAt.IsZero() {
+			continue
+		}
+		if filepath.Base(fileName) != legacyFileName(note.Title) {
+			continue
+		}
+		if note.UpdatedAt.IsZero() {
+			note.UpdatedAt = note.CreatedAt
+		}
+		err = store.Create(&note)
+		if err != nil {
+			return err
+		}
+		os.Rename(fileName, fileName+".bak")
+	}
+	return nil
+}
+
+// legacyFileName is the name older versions saved a note with the title
+// under.
+func legacyFileName(title string) string {
+	return strings.ToLower(strings.ReplaceAll(title, " ", "_")) + ".json"
+}
+
+func slug(title string) string {
+	id := strings.Trim(separators.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	if len(id) > maxSlugLength {
+		id = strings.TrimRight(id[:maxSlugLength], "-")
+	}
+	if id == "" {
+		return "note"
+	}
+	return id
+}
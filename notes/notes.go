@@ -2,11 +2,18 @@ package main
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"example.com/notes/note"
+	"example.com/notes/search"
 	"example.com/notes/todo"
 )
 
@@ -14,65 +21,388 @@ type saver interface {
 	Save() error
 }
 
+type displayer interface {
+	Display()
+}
+
 type outputable interface {
 	saver
-	Display()
+	displayer
 }
 
+var reader = bufio.NewReader(os.Stdin)
+
 func main() {
+	note.Passphrase = askPassphrase
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[0], os.Args[1:]))
+	}
+	manageNotes()
+	manageTodos()
+}
+
+func outputData(data outputable) error {
+	data.Display()
+	return saveData(data)
+}
 
-	title, content := getNoteData()
-	todoText := getUserInput("Add todo: ")
-	todo, err := todo.New(todoText)
+func saveData(data saver) error {
+	err := data.Save()
 	if err != nil {
-		panic(err)
+		fmt.Println(err)
+		return err
 	}
-	todo.Display()
-	err = saveData(todo)
+	fmt.Println("Saved!")
+	return nil
+}
+
+func getNoteData() (string, string) {
+	title := getUserInput("Title: ")
+	content := getContent("Note content", "")
+	return title, content
+}
+
+// manageNotes runs note commands until an empty line or the end of input.
+// Notes are referred to by ID or title.
+func manageNotes() {
+	store, err := note.Open(note.Dir)
 	if err != nil {
+		fmt.Println(err)
 		return
 	}
-	note, err := note.New(title, content)
+	for {
+		command, reference, _ := strings.Cut(getUserInput("Note (new, ls, show ID, edit ID, rm ID, find QUERY, tag [TAG], notebook [NAME], links ID, history ID, diff ID A [B], restore ID N, empty for todos): "), " ")
+		reference = strings.TrimSpace(reference)
+		switch command {
+		case "":
+			return
+		case "new":
+			title, content := getNoteData()
+			created, err := note.New(title, content)
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			created.Tags = note.ParseTags(getUserInput("Tags (comma separated, optional): "))
+			created.Notebook = strings.TrimSpace(getUserInput("Notebook (optional): "))
+			outputData(&created)
+		case "ls":
+			listNotes(store)
+		case "show":
+			found, err := store.Find(reference)
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			found.Display()
+		case "edit":
+			editNote(store, reference)
+		case "find":
+			findNotes(store, reference)
+		case "tag", "notebook":
+			listGroup(store, command == "notebook", reference)
+		case "links":
+			showLinks(store, reference)
+		case "history", "diff", "restore":
+			err := manageRevisions(store, command, reference)
+			if err != nil {
+				fmt.Println(err)
+			}
+		case "rm":
+			found, err := store.Find(reference)
+			if err == nil {
+				err = store.Delete(found.ID)
+			}
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			fmt.Println("Deleted", found.ID)
+		default:
+			fmt.Println("unknown note command")
+		}
+	}
+}
+
+func listNotes(store *note.Store) {
+	notes, err := store.List()
 	if err != nil {
-		panic(err)
+		fmt.Println(err)
+		return
+	}
+	printNotes(notes)
+}
+
+func printNotes(notes []note.Note) {
+	if len(notes) == 0 {
+		fmt.Println("No notes")
 	}
-	err = outputData(note)
+	for _, item := range notes {
+		fmt.Printf("%-24s %-32s %s\n", item.ID, item.Title, item.UpdatedAt.Format("2006-01-02 15:04"))
+	}
+}
 
+// listGroup lists the notes with a tag or in a notebook. Without a name it
+// lists the tags or notebooks with their number of notes.
+func listGroup(store *note.Store, notebooks bool, name string) {
+	notes, err := store.List()
 	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if name != "" && notebooks {
+		printNotes(note.InNotebook(notes, name))
+		return
+	}
+	if name != "" {
+		printNotes(note.Tagged(notes, name))
 		return
 	}
+	counts := note.Count(notes, notebooks)
+	names := slices.Sorted(maps.Keys(counts))
+	if len(names) == 0 {
+		fmt.Println("Nothing found")
+	}
+	for _, name := range names {
+		fmt.Printf("%-24s %d\n", name, counts[name])
+	}
+}
 
+// showLinks prints the notes a note links to, marking links to missing
+// notes, and the notes linking back to it.
+func showLinks(store *note.Store, reference string) {
+	found, err := store.Find(reference)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	notes, err := store.List()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println("Links:")
+	for _, link := range note.Links(found.Content) {
+		target, ok := note.Resolve(notes, link)
+		if !ok {
+			fmt.Printf("  %-24s (missing)\n", link)
+			continue
+		}
+		fmt.Printf("  %-24s %s\n", target.ID, target.Title)
+	}
+	fmt.Println("Backlinks:")
+	for _, source := range note.Backlinks(notes, found) {
+		fmt.Printf("  %-24s %s\n", source.ID, source.Title)
+	}
 }
 
-func outputData(data outputable) error {
-	data.Display()
-	return saveData(data)
+// editNote asks for a new title, content, tags and notebook; empty answers
+// keep the old ones.
+func editNote(store *note.Store, reference string) {
+	found, err := store.Find(reference)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if title := getUserInput("Title [" + found.Title + "]: "); title != "" {
+		found.Title = title
+	}
+	if content := getContent("Note content, empty keeps it", found.Content); content != "" {
+		found.Content = content
+	}
+	if tags := getUserInput("Tags [" + strings.Join(found.Tags, ", ") + "]: "); tags != "" {
+		found.Tags = note.ParseTags(tags)
+	}
+	if notebook := getUserInput("Notebook [" + found.Notebook + "]: "); notebook != "" {
+		found.Notebook = strings.TrimSpace(notebook)
+	}
+	saveData(&found)
 }
 
-func saveData(data saver) error {
-	err := data.Save()
+// findNotes searches notes and todos. Words must all occur; OR matches
+// either side, "quotes" match a phrase and a trailing * a prefix.
+func findNotes(store *note.Store, query string) {
+	index, err := openIndex(store)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	results, err := index.Search(query, time.Now())
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	printResults(results)
+}
+
+func printResults(results []search.Result) {
+	if len(results) == 0 {
+		fmt.Println("Nothing found")
+	}
+	for _, result := range results {
+		fmt.Printf("%-5s %-24s %-40s %s\n", result.Kind, result.ID, result.Label, result.Updated.Format("2006-01-02"))
+	}
+}
+
+// openIndex loads the search index, building it from all notes and todos
+// when it is missing or was damaged. For encrypted notes the index is only
+// built in memory, as it would give their words away.
+func openIndex(store *note.Store) (*search.Index, error) {
+	fileName := search.Path(note.Dir)
+	index, err := search.Load(fileName)
+	if err == nil && !store.Encrypted() {
+		return index, nil
+	}
+	index = search.New(fileName)
+	notes, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range notes {
+		index.Add(item.Document())
+	}
+	list, err := todo.Load(todo.ListFile)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range list.Todos {
+		index.Add(item.Document())
+	}
+	if store.Encrypted() {
+		return index, nil
+	}
+	return index, index.Save()
+}
+
+// manageTodos runs todo commands until an empty line or the end of input.
+func manageTodos() {
+	list, err := todo.Load(todo.ListFile)
 	if err != nil {
 		fmt.Println(err)
+		return
+	}
+	for {
+		fields := strings.Fields(getUserInput("Todo (add, done ID, reopen ID, rm ID, ls [filters], agenda, empty to quit): "))
+		if len(fields) == 0 {
+			return
+		}
+		command, args := fields[0], fields[1:]
+		if command == "ls" {
+			listTodos(list, args)
+			continue
+		}
+		if command == "agenda" {
+			list.Agenda(todo.SystemClock).Display()
+			continue
+		}
+		if command == "add" {
+			err = addTodo(list)
+		} else if command == "done" || command == "reopen" || command == "rm" {
+			_, err = changeTodo(list, command, args)
+		} else {
+			fmt.Println("unknown todo command")
+			continue
+		}
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		saveData(list)
+	}
+}
+
+func addTodo(list *todo.List) error {
+	text := getUserInput("Todo: ")
+	priority := getUserInput("Priority (low, normal, high): ")
+	due, atTime, err := todo.ParseDue(getUserInput("Due (YYYY-MM-DD [HH:MM], optional): "))
+	if err != nil {
 		return err
 	}
-	fmt.Println("Saved!")
+	remind, err := todo.ParseRemind(getUserInput("Remind at (YYYY-MM-DD HH:MM, or 30m before due, optional): "), due)
+	if err != nil {
+		return err
+	}
+	added, err := list.Add(text, priority, due, atTime, remind)
+	if err != nil {
+		return err
+	}
+	added.Display()
 	return nil
 }
 
-func getNoteData() (string, string) {
-	title := getUserInput("Title: ")
-	content := getUserInput("Note content: ")
-	return title, content
+// changeTodo completes, reopens or deletes a todo and returns it as changed.
+func changeTodo(list *todo.List, command string, args []string) (todo.Todo, error) {
+	if len(args) != 1 {
+		return todo.Todo{}, invalidInput(fmt.Errorf("usage: %s ID", command))
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+	if err != nil {
+		return todo.Todo{}, invalidInput(fmt.Errorf("invalid todo id %q", args[0]))
+	}
+	switch command {
+	case "done":
+		return list.Complete(id)
+	case "reopen":
+		return list.Reopen(id)
+	}
+	removed, err := list.Get(id)
+	if err != nil {
+		return todo.Todo{}, err
+	}
+	return removed, list.Delete(id)
+}
+
+// listTodos prints the todos matching the filters: open or done, a
+// priority, due:YYYY-MM-DD for todos due up to that day, and any other word
+// to search the text.
+func listTodos(list *todo.List, args []string) {
+	filter, err := todoFilter(args)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	todoList(list.Find(filter)).Display()
+}
+
+func todoFilter(args []string) (todo.Filter, error) {
+	var filter todo.Filter
+	for _, arg := range args {
+		switch {
+		case arg == todo.Open || arg == todo.Done:
+			filter.Status = arg
+		case arg == todo.Low || arg == todo.Normal || arg == todo.High:
+			filter.Priority = arg
+		case strings.HasPrefix(arg, "due:"):
+			due, _, err := todo.ParseDue(strings.TrimPrefix(arg, "due:"))
+			if err != nil || due == nil {
+				return todo.Filter{}, errors.New("due date must be formatted as YYYY-MM-DD")
+			}
+			filter.DueBefore = due.AddDate(0, 0, 1)
+		default:
+			filter.Text = arg
+		}
+	}
+	return filter, nil
 }
 
 func getUserInput(prompt string) string {
 	fmt.Print(prompt)
-	reader := bufio.NewReader(os.Stdin)
+	text, ok := readLine()
+	if !ok {
+		fmt.Println()
+		os.Exit(0)
+	}
+	return text
+}
+
+// readLine reads a line without its line ending. It reports false at the
+// end of input.
+func readLine() (string, bool) {
 	text, err := reader.ReadString('\n')
-	if err != nil {
-		panic(err)
+	if err != nil && (err != io.EOF || text == "") {
+		return "", false
 	}
 	text = strings.TrimSuffix(text, "\n")
 	text = strings.TrimSuffix(text, "\r")
-	return text
+	return text, true
 }
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"example.com/notes/note"
+)
+
+// askPassphrase reads the passphrase of encrypted notes from
+// NOTES_PASSPHRASE or else from the terminal.
+func askPassphrase() (string, error) {
+	if passphrase := os.Getenv("NOTES_PASSPHRASE"); passphrase != "" {
+		return passphrase, nil
+	}
+	return readSecret("Passphrase: "), nil
+}
+
+// newPassphrase asks for a passphrase twice, unless it is set in
+// NOTES_PASSPHRASE.
+func newPassphrase() (string, error) {
+	if passphrase := os.Getenv("NOTES_PASSPHRASE"); passphrase != "" {
+		return passphrase, nil
+	}
+	passphrase := readSecret("New passphrase: ")
+	if readSecret("Repeat passphrase: ") != passphrase {
+		return "", errors.New("passphrases do not match")
+	}
+	return passphrase, nil
+}
+
+// readSecret reads a line without echoing it where stty is available. The
+// prompt goes to stderr so it does not mix with JSON output.
+func readSecret(prompt string) string {
+	fmt.Fprint(os.Stderr, prompt)
+	if setEcho(false) == nil {
+		defer setEcho(true)
+	}
+	text, _ := readLine()
+	fmt.Fprintln(os.Stderr)
+	return text
+}
+
+func setEcho(on bool) error {
+	mode := "-echo"
+	if on {
+		mode = "echo"
+	}
+	command := exec.Command("stty", mode)
+	command.Stdin = os.Stdin
+	return command.Run()
+}
+
+// encryptNotes encrypts or decrypts the notes directory in place.
+func encryptNotes(dir string, encrypt bool) error {
+	_, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("notes directory %s does not exist", dir)
+	}
+	store, err := note.Open(dir)
+	if err != nil {
+		return err
+	}
+	if !encrypt {
+		return store.Decrypt()
+	}
+	// A store that is already encrypted keeps its key; Encrypt only finishes
+	// an interrupted run then.
+	var passphrase string
+	if !store.Encrypted() {
+		passphrase, err = newPassphrase()
+		if err != nil {
+			return err
+		}
+	}
+	return store.Encrypt(passphrase)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	"example.com/notes/todo"
+)
+
+// remindTodos notifies about todos as they fall due, checking every
+// interval until the process is interrupted. With once it checks a single
+// time, for running it from cron.
+func remindTodos(clock todo.Clock, out io.Writer, interval time.Duration, once, asJSON bool) error {
+	if interval <= 0 {
+		return invalidInput(errors.New("the interval must be positive"))
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		err := notify(clock, out, asJSON)
+		if err != nil {
+			if once {
+				return err
+			}
+			fmt.Fprintln(os.Stderr, "notes:", err)
+		}
+		if once {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// notify writes the notifications that are due, one per line, and saves
+// the list so they are not repeated.
+func notify(clock todo.Clock, out io.Writer, asJSON bool) error {
+	list, err := todo.Load(todo.ListFile)
+	if err != nil {
+		return err
+	}
+	notifications := list.Remind(clock)
+	if len(notifications) == 0 {
+		return nil
+	}
+	err = list.Save()
+	if err != nil {
+		return err
+	}
+	for _, notification := range notifications {
+		if asJSON {
+			data, _ := json.Marshal(notification)
+			fmt.Fprintf(out, "%s\n", data)
+		} else {
+			fmt.Fprintln(out, notification)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package search
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// IndexFile is kept next to todos.json and the notes directory.
+const IndexFile = "search_index.json"
+
+const labelLength = 60
+
+var ErrNoIndex = errors.New("search index does not exist")
+
+// Document is a note or todo as it is indexed. Title words rank higher
+// than words of the text.
+type Document struct {
+	Kind    string
+	ID      string
+	Title   string
+	Text    string
+	Updated time.Time
+}
+
+func (document Document) Key() string {
+	return document.Kind + ":" + document.ID
+}
+
+type entry struct {
+	Kind        string    `json:"kind"`
+	ID          string    `json:"id"`
+	Label       string    `json:"label"`
+	Updated     time.Time `json:"updated"`
+	TitleLength int       `json:"title_length"`
+}
+
+// Index is an inverted index: for every word the documents it appears in
+// and the word positions in each, which phrase queries need.
+type Index struct {
+	fileName  string
+	Documents map[string]entry            `json:"documents"`
+	Terms     map[string]map[string][]int `json:"terms"`
+}
+
+func New(fileName string) *Index {
+	return &Index{
+		fileName:  fileName,
+		Documents: map[string]entry{},
+		Terms:     map[string]map[string][]int{},
+	}
+}
+
+// Path returns the index file next to the given notes directory or todo
+// list file.
+func Path(name string) string {
+	return filepath.Join(filepath.Dir(filepath.Clean(name)), IndexFile)
+}
+
+func Load(fileName string) (*Index, error) {
+	data, err := os.ReadFile(fileName)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoIndex
+	}
+	if err != nil {
+		return nil, errors.New("failed to read search index")
+	}
+	index := New(fileName)
+	err = json.Unmarshal(data, index)
+	if err != nil || index.Documents == nil || index.Terms == nil {
+		return nil, errors.New("failed to parse search index")
+	}
+	return index, nil
+}
+
+func (index *Index) Save() error {
+	data, err := json.Marshal(index)
+	if err != nil {
+		return errors.New("failed to convert search index to json")
+	}
+	return os.WriteFile(index.fileName, data, 0644)
+}
+
+// Add indexes the document, replacing an earlier version of it.
+func (index *Index) Add(document Document) {
+	key := document.Key()
+	index.Remove(key)
+	title := tokenize(document.Title)
+	words := append(title, tokenize(document.Text)...)
+	for position, word := range words {
+		if index.Terms[word] == nil {
+			index.Terms[word] = map[string][]int{}
+		}
+		// Title and text are one position apart, so a phrase does not
+		// match across them.
+		if position >= len(title) {
+			position++
+		}
+		index.Terms[word][key] = append(index.Terms[word][key], position)
+	}
+	label := document.Title
+	if label == "" {
+		label = document.Text
+	}
+	if runes := []rune(label); len(runes) > labelLength {
+		label = string(runes[:labelLength]) + "..."
+	}
+	index.Documents[key] = entry{
+		Kind:        document.Kind,
+		ID:          document.ID,
+		Label:       label,
+		Updated:     document.Updated,
+		TitleLength: len(title),
+	}
+}
+
+func (index *Index) Remove(key string) {
+	if _, ok := index.Documents[key]; !ok {
+		return
+	}
+	delete(index.Documents, key)
+	for word, postings := range index.Terms {
+		delete(postings, key)
+		if len(postings) == 0 {
+			delete(index.Terms, word)
+		}
+	}
+}
+
+// Update adds the documents to the index file and removes the given keys.
+// The index only speeds up search, so a damaged index file is removed to
+// be rebuilt by the next search instead of failing the save.
+func Update(fileName string, documents []Document, removed []string) error {
+	index, err := Load(fileName)
+	if errors.Is(err, ErrNoIndex) {
+		return nil
+	}
+	if err != nil {
+		os.Remove(fileName)
+		return nil
+	}
+	for _, key := range removed {
+		index.Remove(key)
+	}
+	for _, document := range documents {
+		index.Add(document)
+	}
+	return index.Save()
+}
+
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
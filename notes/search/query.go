@@ -0,0 +1,196 @@
+package search
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+const titleWeight = 3
+
+// Recently updated documents rank up to recencyBoost higher; the boost
+// halves about every recencyHalfLife.
+const (
+	recencyBoost    = 0.5
+	recencyHalfLife = 30 * 24 * time.Hour
+)
+
+var ErrEmptyQuery = errors.New("search query is empty")
+
+type Result struct {
+	Kind    string    `json:"kind"`
+	ID      string    `json:"id"`
+	Label   string    `json:"label"`
+	Updated time.Time `json:"updated"`
+	Score   float64   `json:"score"`
+}
+
+// clause is one part of a query: a word, a word prefix ending in * or a
+// "quoted phrase".
+type clause struct {
+	words  []string
+	prefix bool
+}
+
+// Search finds the documents matching the query. Clauses separated by
+// spaces must all match; OR between clauses matches either side. Results
+// are ranked by how often and where the words occur, how rare they are and
+// how recently the document changed.
+func (index *Index) Search(query string, now time.Time) ([]Result, error) {
+	groups := parse(query)
+	if len(groups) == 0 {
+		return nil, ErrEmptyQuery
+	}
+	scores := map[string]float64{}
+	for _, group := range groups {
+		for key, score := range index.matchAll(group) {
+			scores[key] += score
+		}
+	}
+
+	results := []Result{}
+	for key, score := range scores {
+		document := index.Documents[key]
+		age := now.Sub(document.Updated)
+		boost := 1 + recencyBoost*math.Pow(0.5, max(age, 0).Hours()/recencyHalfLife.Hours())
+		results = append(results, Result{
+			Kind:    document.Kind,
+			ID:      document.ID,
+			Label:   document.Label,
+			Updated: document.Updated,
+			Score:   score * boost,
+		})
+	}
+	slices.SortFunc(results, func(a, b Result) int {
+		if a.Score != b.Score {
+			if a.Score > b.Score {
+				return -1
+			}
+			return 1
+		}
+		return b.Updated.Compare(a.Updated)
+	})
+	return results, nil
+}
+
+// parse splits the query into groups joined by OR, each a list of clauses
+// that all have to match.
+func parse(query string) [][]clause {
+	groups := [][]clause{}
+	group := []clause{}
+	for query = strings.TrimSpace(query); query != ""; query = strings.TrimSpace(query) {
+		var part string
+		phrase := false
+		if query[0] == '"' {
+			end := strings.IndexByte(query[1:], '"')
+			if end < 0 {
+				part, query = query[1:], ""
+			} else {
+				part, query = query[1:end+1], query[end+2:]
+			}
+			phrase = true
+		} else {
+			part, query, _ = strings.Cut(query, " ")
+		}
+		if part == "OR" && !phrase {
+			if len(group) > 0 {
+				groups = append(groups, group)
+			}
+			group = []clause{}
+			continue
+		}
+		if part == "AND" && !phrase {
+			continue
+		}
+		prefix := !phrase && strings.HasSuffix(part, "*")
+		words := tokenize(part)
+		if len(words) == 0 {
+			continue
+		}
+		group = append(group, clause{words: words, prefix: prefix && len(words) == 1})
+	}
+	if len(group) > 0 {
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// matchAll scores the documents matching every clause of the group.
+func (index *Index) matchAll(group []clause) map[string]float64 {
+	var scores map[string]float64
+	for _, clause := range group {
+		matched := index.match(clause)
+		if scores == nil {
+			scores = matched
+			continue
+		}
+		for key := range scores {
+			score, ok := matched[key]
+			if !ok {
+				delete(scores, key)
+				continue
+			}
+			scores[key] += score
+		}
+	}
+	return scores
+}
+
+// match scores the documents matching one clause: the number of matches,
+// with title matches counting more, times the inverse document frequency.
+func (index *Index) match(clause clause) map[string]float64 {
+	hits := map[string][]int{}
+	if clause.prefix {
+		for word, postings := range index.Terms {
+			if !strings.HasPrefix(word, clause.words[0]) {
+				continue
+			}
+			for key, positions := range postings {
+				hits[key] = append(hits[key], positions...)
+			}
+		}
+	} else {
+		hits = index.phrase(clause.words)
+	}
+
+	scores := map[string]float64{}
+	idf := math.Log(1 + float64(len(index.Documents))/float64(max(len(hits), 1)))
+	for key, positions := range hits {
+		titleLength := index.Documents[key].TitleLength
+		weight := 0.0
+		for _, position := range positions {
+			if position < titleLength {
+				weight += titleWeight
+			} else {
+				weight++
+			}
+		}
+		scores[key] = weight * idf
+	}
+	return scores
+}
+
+// phrase returns, per document, the positions where the words occur one
+// after the other.
+func (index *Index) phrase(words []string) map[string][]int {
+	hits := map[string][]int{}
+	for key, positions := range index.Terms[words[0]] {
+		for _, start := range positions {
+			if index.followedBy(key, start, words[1:]) {
+				hits[key] = append(hits[key], start)
+			}
+		}
+	}
+	return hits
+}
+
+func (index *Index) followedBy(key string, start int, words []string) bool {
+	for offset, word := range words {
+		if !slices.Contains(index.Terms[word][key], start+offset+1) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,151 @@
+package todo
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Clock tells the time. The agenda and reminders take it as a parameter so
+// they can be run at any moment.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// SystemClock is the clock of the computer.
+var SystemClock Clock = systemClock{}
+
+// Agenda groups the open todos by when they are due. Todos without a due
+// date are at the end of Later.
+type Agenda struct {
+	Overdue  []Todo `json:"overdue"`
+	Today    []Todo `json:"today"`
+	ThisWeek []Todo `json:"this_week"`
+	Later    []Todo `json:"later"`
+}
+
+// Notification tells that a todo became due or that its reminder time has
+// come.
+type Notification struct {
+	Kind string    `json:"kind"`
+	Time time.Time `json:"time"`
+	Todo Todo      `json:"todo"`
+}
+
+func (notification Notification) String() string {
+	return fmt.Sprintf("%s %s: #%d %s", notification.Time.Format(timeLayout), notification.Kind, notification.Todo.ID, notification.Todo.Text)
+}
+
+// Agenda returns the open todos grouped into overdue, due today, due later
+// this week (which ends on Sunday) and later.
+func (list *List) Agenda(clock Clock) Agenda {
+	now := clock.Now()
+	tomorrow := startOfDay(now).AddDate(0, 0, 1)
+	weekEnd := startOfDay(now).AddDate(0, 0, 7-(int(now.Weekday())+6)%7)
+	todos := list.Find(Filter{Status: Open})
+	slices.SortStableFunc(todos, func(a, b Todo) int {
+		switch {
+		case a.Due == nil && b.Due == nil:
+			return 0
+		case a.Due == nil:
+			return 1
+		case b.Due == nil:
+			return -1
+		}
+		return a.Due.Compare(*b.Due)
+	})
+	agenda := Agenda{Overdue: []Todo{}, Today: []Todo{}, ThisWeek: []Todo{}, Later: []Todo{}}
+	for _, todo := range todos {
+		switch {
+		case todo.Due == nil:
+			agenda.Later = append(agenda.Later, todo)
+		case todo.Overdue(now):
+			agenda.Overdue = append(agenda.Overdue, todo)
+		case todo.Due.Before(tomorrow):
+			agenda.Today = append(agenda.Today, todo)
+		case todo.Due.Before(weekEnd):
+			agenda.ThisWeek = append(agenda.ThisWeek, todo)
+		default:
+			agenda.Later = append(agenda.Later, todo)
+		}
+	}
+	return agenda
+}
+
+func (agenda Agenda) Display() {
+	groups := []struct {
+		title string
+		todos []Todo
+	}{
+		{"Overdue", agenda.Overdue},
+		{"Today", agenda.Today},
+		{"This week", agenda.ThisWeek},
+		{"Later", agenda.Later},
+	}
+	for _, group := range groups {
+		if len(group.todos) == 0 {
+			continue
+		}
+		fmt.Println(group.title)
+		for _, todo := range group.todos {
+			fmt.Print("  ")
+			todo.Display()
+		}
+	}
+	if len(agenda.Overdue)+len(agenda.Today)+len(agenda.ThisWeek)+len(agenda.Later) == 0 {
+		fmt.Println("Nothing to do")
+	}
+}
+
+// Remind returns a notification for each open todo whose reminder or due
+// time has come since it was last notified, and marks them as notified.
+// A todo due on a day without a time is due from the start of that day.
+// Save the list afterwards to not notify again.
+func (list *List) Remind(clock Clock) []Notification {
+	now := clock.Now()
+	notifications := []Notification{}
+	for i := range list.Todos {
+		todo := &list.Todos[i]
+		if todo.Status != Open {
+			continue
+		}
+		var latest *Notification
+		events := []Notification{}
+		if todo.Remind != nil {
+			events = append(events, Notification{Kind: "reminder", Time: *todo.Remind})
+		}
+		if todo.Due != nil {
+			events = append(events, Notification{Kind: "due", Time: *todo.Due})
+		}
+		for _, event := range events {
+			if event.Time.After(now) || todo.Notified != nil && !todo.Notified.Before(event.Time) {
+				continue
+			}
+			if latest == nil || !event.Time.Before(latest.Time) {
+				latest = &event
+			}
+		}
+		if latest == nil {
+			continue
+		}
+		if todo.Overdue(now) {
+			latest.Kind = "overdue"
+		}
+		todo.Notified = &now
+		list.changed[todo.ID] = true
+		latest.Todo = *todo
+		notifications = append(notifications, *latest)
+	}
+	return notifications
+}
+
+func startOfDay(moment time.Time) time.Time {
+	year, month, day := moment.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, moment.Location())
+}
@@ -0,0 +1,179 @@
+package todo
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+type fixedClock time.Time
+
+func (clock fixedClock) Now() time.Time {
+	return time.Time(clock)
+}
+
+// at returns a moment in October 2026. The 14th is a Wednesday.
+func at(day, hour, minute int) time.Time {
+	return time.Date(2026, time.October, day, hour, minute, 0, 0, time.Local)
+}
+
+func newList(t *testing.T) *List {
+	t.Helper()
+	list, err := Load(filepath.Join(t.TempDir(), ListFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return list
+}
+
+func add(t *testing.T, list *List, text, due, remind string) Todo {
+	t.Helper()
+	dueTime, atTime, err := ParseDue(due)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remindTime, err := ParseRemind(remind, dueTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	added, err := list.Add(text, Normal, dueTime, atTime, remindTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return added
+}
+
+func texts(todos []Todo) []string {
+	result := []string{}
+	for _, todo := range todos {
+		result = append(result, todo.Text)
+	}
+	return result
+}
+
+func TestAgenda(t *testing.T) {
+	list := newList(t)
+	add(t, list, "yesterday", "2026-10-13", "")
+	add(t, list, "this morning", "2026-10-14 09:00", "")
+	add(t, list, "midnight today", "2026-10-14 00:00", "")
+	add(t, list, "today", "2026-10-14", "")
+	add(t, list, "this evening", "2026-10-14 18:00", "")
+	add(t, list, "sunday", "2026-10-18 23:00", "")
+	add(t, list, "monday", "2026-10-19", "")
+	add(t, list, "someday", "", "")
+	done := add(t, list, "done yesterday", "2026-10-13", "")
+	list.Complete(done.ID)
+
+	agenda := list.Agenda(fixedClock(at(14, 10, 0)))
+	groups := []struct {
+		name string
+		got  []Todo
+		want []string
+	}{
+		{"overdue", agenda.Overdue, []string{"yesterday", "midnight today", "this morning"}},
+		{"today", agenda.Today, []string{"today", "this evening"}},
+		{"this week", agenda.ThisWeek, []string{"sunday"}},
+		{"later", agenda.Later, []string{"monday", "someday"}},
+	}
+	for _, group := range groups {
+		if got := texts(group.got); !slices.Equal(got, group.want) {
+			t.Errorf("%s = %q, want %q", group.name, got, group.want)
+		}
+	}
+}
+
+func TestDueDateOrTime(t *testing.T) {
+	tests := []struct {
+		due     string
+		hasTime bool
+		overdue time.Time
+	}{
+		{"2026-10-14", false, at(15, 0, 0)},
+		{"2026-10-14 00:00", true, at(14, 0, 1)},
+		{"2026-10-14 15:30", true, at(14, 15, 31)},
+	}
+	for _, test := range tests {
+		t.Run(test.due, func(t *testing.T) {
+			todo := add(t, newList(t), "todo", test.due, "")
+			if todo.HasTime() != test.hasTime {
+				t.Errorf("HasTime() = %t, want %t", todo.HasTime(), test.hasTime)
+			}
+			if todo.Overdue(test.overdue.Add(-time.Minute)) || !todo.Overdue(test.overdue) {
+				t.Errorf("not overdue from %s on", test.overdue.Format(timeLayout))
+			}
+		})
+	}
+}
+
+func TestDueTimeSurvivesSaving(t *testing.T) {
+	list := newList(t)
+	add(t, list, "midnight", "2026-10-14 00:00", "")
+	err := list.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(list.fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.Todos[0].HasTime() {
+		t.Error("todo due at midnight lost its time after loading")
+	}
+}
+
+func TestRemindFiresOnce(t *testing.T) {
+	list := newList(t)
+	added := add(t, list, "call", "2026-10-14 12:00", "30m")
+
+	steps := []struct {
+		now  time.Time
+		want []string
+	}{
+		{at(14, 11, 0), []string{}},
+		{at(14, 11, 30), []string{"reminder"}},
+		{at(14, 11, 45), []string{}},
+		{at(14, 12, 1), []string{"overdue"}},
+		{at(14, 13, 0), []string{}},
+	}
+	for _, step := range steps {
+		kinds := []string{}
+		for _, notification := range list.Remind(fixedClock(step.now)) {
+			kinds = append(kinds, notification.Kind)
+		}
+		if !slices.Equal(kinds, step.want) {
+			t.Errorf("at %s: notifications = %q, want %q", step.now.Format(timeLayout), kinds, step.want)
+		}
+	}
+
+	list.Complete(added.ID)
+	if notifications := list.Remind(fixedClock(at(14, 14, 0))); len(notifications) != 0 {
+		t.Errorf("done todo notified: %v", notifications)
+	}
+	reopened, err := list.Reopen(added.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.Notified != nil {
+		t.Error("Reopen kept the notification time")
+	}
+	if notifications := list.Remind(fixedClock(at(14, 14, 0))); len(notifications) != 1 {
+		t.Errorf("reopened todo: %d notifications, want 1", len(notifications))
+	}
+}
+
+func TestDateOnlyDueNotifiesFromStartOfDay(t *testing.T) {
+	list := newList(t)
+	add(t, list, "report", "2026-10-14", "")
+
+	if notifications := list.Remind(fixedClock(at(13, 23, 59))); len(notifications) != 0 {
+		t.Errorf("notified before the due day: %v", notifications)
+	}
+	notifications := list.Remind(fixedClock(at(14, 8, 0)))
+	if len(notifications) != 1 || notifications[0].Kind != "due" {
+		t.Errorf("on the due day: %v, want one due notification", notifications)
+	}
+	if notifications := list.Remind(fixedClock(at(14, 9, 0))); len(notifications) != 0 {
+		t.Errorf("notified twice: %v", notifications)
+	}
+}
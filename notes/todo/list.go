@@ -0,0 +1,235 @@
+package todo
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"example.com/notes/fileutils"
+	"example.com/notes/search"
+)
+
+// ListFile holds all todos. Older versions kept a single todo in
+// legacyFile, which is imported the first time the list is loaded.
+const ListFile = "todos.json"
+const legacyFile = "todo.json"
+
+var ErrNotFound = errors.New("todo does not exist")
+
+type List struct {
+	fileName string
+	changed  map[int]bool
+	NextID   int    `json:"next_id"`
+	Todos    []Todo `json:"todos"`
+}
+
+// Filter selects todos for Find. Empty fields match every todo.
+type Filter struct {
+	Status    string
+	Priority  string
+	DueBefore time.Time
+	Text      string
+}
+
+func Load(fileName string) (*List, error) {
+	list := &List{fileName: fileName, changed: map[int]bool{}, NextID: 1, Todos: []Todo{}}
+	data, err := os.ReadFile(fileName)
+	if errors.Is(err, os.ErrNotExist) {
+		list.importLegacy()
+		return list, nil
+	}
+	if err != nil {
+		return nil, errors.New("failed to read todo list")
+	}
+	err = json.Unmarshal(data, list)
+	if err != nil {
+		return nil, errors.New("failed to parse todo list")
+	}
+	return list, nil
+}
+
+func (list *List) Save() error {
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return errors.New("failed to convert todo list to json")
+	}
+	err = fileutils.WriteAtomic(list.fileName, data)
+	if err != nil {
+		return err
+	}
+	return list.updateIndex()
+}
+
+// updateIndex passes the todos changed since the last save on to the
+// search index.
+func (list *List) updateIndex() error {
+	documents := []search.Document{}
+	removed := []string{}
+	for id := range list.changed {
+		todo, err := list.Get(id)
+		if err != nil {
+			removed = append(removed, Todo{ID: id}.Document().Key())
+			continue
+		}
+		documents = append(documents, todo.Document())
+	}
+	list.changed = map[int]bool{}
+	return search.Update(search.Path(list.fileName), documents, removed)
+}
+
+// Add adds a todo. atTime tells that it is due at the time of due rather
+// than on its day, see ParseDue.
+func (list *List) Add(text, priority string, due *time.Time, atTime bool, remind *time.Time) (Todo, error) {
+	todo, err := New(text)
+	if err != nil {
+		return Todo{}, err
+	}
+	todo.Priority, err = ParsePriority(priority)
+	if err != nil {
+		return Todo{}, err
+	}
+	todo.Due = due
+	todo.DueAtTime = due != nil && atTime
+	todo.Remind = remind
+	return list.add(todo), nil
+}
+
+func (list *List) Get(id int) (Todo, error) {
+	index := list.index(id)
+	if index < 0 {
+		return Todo{}, ErrNotFound
+	}
+	return list.Todos[index], nil
+}
+
+func (list *List) Complete(id int) (Todo, error) {
+	index := list.index(id)
+	if index < 0 {
+		return Todo{}, ErrNotFound
+	}
+	now := time.Now()
+	list.Todos[index].Status = Done
+	list.Todos[index].CompletedAt = &now
+	list.changed[id] = true
+	return list.Todos[index], nil
+}
+
+func (list *List) Reopen(id int) (Todo, error) {
+	index := list.index(id)
+	if index < 0 {
+		return Todo{}, ErrNotFound
+	}
+	list.Todos[index].Status = Open
+	list.Todos[index].CompletedAt = nil
+	list.Todos[index].Notified = nil
+	list.changed[id] = true
+	return list.Todos[index], nil
+}
+
+func (list *List) Delete(id int) error {
+	index := list.index(id)
+	if index < 0 {
+		return ErrNotFound
+	}
+	list.Todos = slices.Delete(list.Todos, index, index+1)
+	list.changed[id] = true
+	return nil
+}
+
+// Find returns the todos matching the filter, open ones first, then by
+// priority and due date.
+func (list *List) Find(filter Filter) []Todo {
+	found := []Todo{}
+	for _, todo := range list.Todos {
+		if filter.Status != "" && todo.Status != filter.Status {
+			continue
+		}
+		if filter.Priority != "" && todo.Priority != filter.Priority {
+			continue
+		}
+		if !filter.DueBefore.IsZero() && (todo.Due == nil || !todo.Due.Before(filter.DueBefore)) {
+			continue
+		}
+		if filter.Text != "" && !strings.Contains(strings.ToLower(todo.Text), strings.ToLower(filter.Text)) {
+			continue
+		}
+		found = append(found, todo)
+	}
+	slices.SortStableFunc(found, compare)
+	return found
+}
+
+func compare(a, b Todo) int {
+	if a.Status != b.Status {
+		if a.Status == Open {
+			return -1
+		}
+		return 1
+	}
+	if rank(a.Priority) != rank(b.Priority) {
+		return rank(b.Priority) - rank(a.Priority)
+	}
+	switch {
+	case a.Due == nil && b.Due == nil:
+		return a.ID - b.ID
+	case a.Due == nil:
+		return 1
+	case b.Due == nil:
+		return -1
+	}
+	return a.Due.Compare(*b.Due)
+}
+
+func rank(priority string) int {
+	switch priority {
+	case High:
+		return 3
+	case Low:
+		return 1
+	}
+	return 2
+}
+
+func (list *List) add(todo Todo) Todo {
+	todo.ID = list.NextID
+	list.NextID++
+	list.Todos = append(list.Todos, todo)
+	list.changed[todo.ID] = true
+	return todo
+}
+
+func (list *List) update(todo Todo) error {
+	index := list.index(todo.ID)
+	if index < 0 {
+		return ErrNotFound
+	}
+	list.Todos[index] = todo
+	list.changed[todo.ID] = true
+	return nil
+}
+
+func (list *List) index(id int) int {
+	return slices.IndexFunc(list.Todos, func(todo Todo) bool {
+		return todo.ID == id
+	})
+}
+
+// importLegacy turns the single todo of older versions into the first item
+// of the list.
+func (list *List) importLegacy() {
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(list.fileName), legacyFile))
+	if err != nil {
+		return
+	}
+	var legacy Todo
+	err = json.Unmarshal(data, &legacy)
+	if err != nil || legacy.Text == "" {
+		return
+	}
+	todo, _ := New(legacy.Text)
+	list.add(todo)
+}
@@ -6,7 +6,27 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
+
+	"example.com/notes/search"
+)
+
+const (
+	Open = "open"
+	Done = "done"
+)
+
+const (
+	Low    = "low"
+	Normal = "normal"
+	High   = "high"
+)
+
+const (
+	dateLayout = "2006-01-02"
+	timeLayout = "2006-01-02 15:04"
 )
 
 func New(text string) (Todo, error) {
@@ -14,12 +34,90 @@ func New(text string) (Todo, error) {
 		return Todo{}, errors.New("text cannot be empty")
 	}
 	return Todo{
-		Text: text,
+		Text:      text,
+		Status:    Open,
+		Priority:  Normal,
+		CreatedAt: time.Now(),
 	}, nil
 }
 
 type Todo struct {
-	Text string `json:"text"`
+	ID          int        `json:"id"`
+	Text        string     `json:"text"`
+	Status      string     `json:"status"`
+	Priority    string     `json:"priority"`
+	Due         *time.Time `json:"due,omitempty"`
+	DueAtTime   bool       `json:"due_at_time,omitempty"`
+	Remind      *time.Time `json:"remind,omitempty"`
+	Notified    *time.Time `json:"notified,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// ParsePriority accepts low, normal and high; empty text means normal.
+func ParsePriority(text string) (string, error) {
+	switch strings.ToLower(text) {
+	case "":
+		return Normal, nil
+	case Low, Normal, High:
+		return strings.ToLower(text), nil
+	}
+	return "", errors.New("priority must be low, normal or high")
+}
+
+// ParseDue reads a due date as YYYY-MM-DD, or YYYY-MM-DD HH:MM when the
+// todo is due at a certain time, and reports which it was; empty text means
+// no due date.
+func ParseDue(text string) (*time.Time, bool, error) {
+	if text == "" {
+		return nil, false, nil
+	}
+	for _, layout := range []string{dateLayout, timeLayout, "2006-01-02T15:04"} {
+		due, err := time.ParseInLocation(layout, strings.TrimSpace(text), time.Local)
+		if err == nil {
+			return &due, layout != dateLayout, nil
+		}
+	}
+	return nil, false, errors.New("due date must be formatted as YYYY-MM-DD or YYYY-MM-DD HH:MM")
+}
+
+// ParseRemind reads a reminder time as YYYY-MM-DD HH:MM, or as a duration
+// like 30m or 2h before the due date; empty text means no reminder.
+func ParseRemind(text string, due *time.Time) (*time.Time, error) {
+	if text == "" {
+		return nil, nil
+	}
+	if before, err := time.ParseDuration(text); err == nil {
+		if due == nil {
+			return nil, errors.New("a reminder before the due date needs a due date")
+		}
+		remind := due.Add(-before)
+		return &remind, nil
+	}
+	remind, err := time.ParseInLocation(timeLayout, strings.TrimSpace(text), time.Local)
+	if err != nil {
+		return nil, errors.New("reminder must be formatted as YYYY-MM-DD HH:MM or as a duration like 30m")
+	}
+	return &remind, nil
+}
+
+// HasTime reports whether the todo is due at a time of day rather than
+// just on a day. Todos saved before DueAtTime was kept count as due at a
+// time unless it is midnight.
+func (todo Todo) HasTime() bool {
+	return todo.Due != nil && (todo.DueAtTime || todo.Due.Hour() != 0 || todo.Due.Minute() != 0)
+}
+
+// Overdue reports whether an open todo is past its due time, or past the
+// end of its due day when it has no time.
+func (todo Todo) Overdue(now time.Time) bool {
+	if todo.Status != Open || todo.Due == nil {
+		return false
+	}
+	if todo.HasTime() {
+		return now.After(*todo.Due)
+	}
+	return !now.Before(todo.Due.AddDate(0, 0, 1))
 }
 
 func GetTodoData() string {
@@ -27,13 +125,54 @@ func GetTodoData() string {
 }
 
 func (todo Todo) Display() {
-	fmt.Printf("Todo: %s \n", todo.Text)
+	check := " "
+	if todo.Status == Done {
+		check = "x"
+	}
+	fmt.Printf("#%d [%s] %s (%s)", todo.ID, check, todo.Text, todo.Priority)
+	if todo.HasTime() {
+		fmt.Printf(" due %s", todo.Due.Format(timeLayout))
+	} else if todo.Due != nil {
+		fmt.Printf(" due %s", todo.Due.Format(dateLayout))
+	}
+	if todo.Remind != nil && todo.Status == Open {
+		fmt.Printf(" remind %s", todo.Remind.Format(timeLayout))
+	}
+	if todo.CompletedAt != nil {
+		fmt.Printf(" done %s", todo.CompletedAt.Format(dateLayout))
+	}
+	fmt.Println()
 }
 
+// Save adds the todo to the list in todos.json, or updates it when it
+// already has an ID.
 func (todo Todo) Save() error {
-	fileName := "todo.json"
-	toDoJson := ConvertToJson(todo)
-	return os.WriteFile(fileName, toDoJson, 0644)
+	list, err := Load(ListFile)
+	if err != nil {
+		return err
+	}
+	if todo.ID == 0 {
+		list.add(todo)
+	} else {
+		err = list.update(todo)
+		if err != nil {
+			return err
+		}
+	}
+	return list.Save()
+}
+
+func (todo Todo) Document() search.Document {
+	updated := todo.CreatedAt
+	if todo.CompletedAt != nil {
+		updated = *todo.CompletedAt
+	}
+	return search.Document{
+		Kind:    "todo",
+		ID:      strconv.Itoa(todo.ID),
+		Text:    todo.Text,
+		Updated: updated,
+	}
 }
 
 func getUserInput(prompt string) string {
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/notes/export"
+	"example.com/notes/note"
+)
+
+// transferResult counts the notes of an export or import.
+type transferResult struct {
+	Path    string `json:"path,omitempty"`
+	Notes   int    `json:"notes"`
+	Skipped int    `json:"skipped"`
+}
+
+func (result transferResult) Display() {
+	if result.Path != "" {
+		fmt.Printf("Exported %d notes to %s\n", result.Notes, result.Path)
+		return
+	}
+	fmt.Printf("Imported %d notes, %d were up to date\n", result.Notes, result.Skipped)
+}
+
+func exportNotes(store *note.Store, path string, asHTML bool) (transferResult, error) {
+	notes, err := store.List()
+	if err != nil {
+		return transferResult{}, err
+	}
+	switch {
+	case export.IsArchive(path):
+		err = export.WriteArchive(path, notes)
+	case asHTML:
+		err = export.WriteSite(path, notes)
+	default:
+		err = export.WriteMarkdown(path, notes)
+	}
+	return transferResult{Path: path, Notes: len(notes)}, err
+}
+
+// importNotes reads all paths before saving anything, so a file that does
+// not parse leaves the store unchanged.
+func importNotes(store *note.Store, paths []string) (transferResult, error) {
+	notes := []note.Note{}
+	for _, path := range paths {
+		read, err := export.Read(path)
+		if err != nil {
+			return transferResult{}, err
+		}
+		notes = append(notes, read...)
+	}
+	result := transferResult{}
+	for _, item := range notes {
+		saved, err := store.Import(&item)
+		if err != nil {
+			return result, err
+		}
+		if saved {
+			result.Notes++
+		} else {
+			result.Skipped++
+		}
+	}
+	return result, nil
+}